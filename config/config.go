@@ -1,7 +1,10 @@
 package config
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"golang.org/x/xerrors"
 	"gopkg.in/yaml.v3"
@@ -9,41 +12,393 @@ import (
 
 const defaultConfigFile = ".readme-sync-config.yml"
 
+// environment variables overriding the configuration file, lists are comma separated
+const (
+	keyEnv                = "README_APIKEY"
+	keyFileEnv            = "README_APIKEY_FILE" // file holding the key, e.g. a mounted secret
+	configEnv             = "README_SYNC_CONFIG"
+	pathEnv               = "README_SYNC_PATH"
+	versionEnv            = "README_VERSION"
+	missingCategoriesEnv  = "README_MISSING_CATEGORIES"
+	unknownFrontMatterEnv = "README_UNKNOWN_FRONT_MATTER"
+	includeEnv            = "README_INCLUDE"
+	excludeEnv            = "README_EXCLUDE"
+	transformsEnv         = "README_TRANSFORMS"
+	projectEnv            = "README_SYNC_PROJECT"
+)
+
+// environment variables with this prefix override entries in the variables map
+const variableEnvPrefix = "README_VAR_"
+
 type CategoryConfig struct {
 	Slug  string `yaml:"slug"`
 	Title string `yaml:"title"`
+	Type  string `yaml:"type,omitempty"`  // guide (default) or reference
+	Order *int   `yaml:"order,omitempty"` // overrides the category file, defaults to the position in the categories list
+}
+
+// VersionConfig describes one readme version synced from the docs tree
+type VersionConfig struct {
+	Version   string            `yaml:"version"`
+	Path      string            `yaml:"path,omitempty"`      // docs root for this version, defaults to the top-level path
+	Overlay   string            `yaml:"overlay,omitempty"`   // folder layered over the docs root, replacing and adding files
+	Variables map[string]string `yaml:"variables,omitempty"` // overrides the top-level variables for this version
+	Specs     []SpecConfig      `yaml:"specs,omitempty"`
+}
+
+// SpecConfig maps an openapi or swagger file to the readme specification it updates
+type SpecConfig struct {
+	Path string `yaml:"path"`
+	Id   string `yaml:"id,omitempty"` // when empty, the specification with the same title is updated or a new one created
+}
+
+// ProjectConfig is one readme project synced from the repository, with its own api key.
+// Settings it leaves unset are inherited from the top level of the configuration file.
+type ProjectConfig struct {
+	Name   string `yaml:"name"`
+	KeyEnv string `yaml:"key_env,omitempty"` // environment variable holding the project's api key, defaults to the usual key sources
+	Config `yaml:",inline"`
 }
 
 type Config struct {
-	Categories []CategoryConfig `yaml:"categories"`
-	Version    string           `yaml:"version"`
-	Key        string           `yaml:"-"`
+	Projects           []ProjectConfig   `yaml:"projects,omitempty"` // alternative to the settings below for syncing several projects
+	Path               string            `yaml:"path"`               // docs root, used by versions without their own path
+	Categories         []CategoryConfig  `yaml:"categories"`
+	MissingCategories  string            `yaml:"missing_categories"` // error (default) or slug to title unconfigured folders by their slug
+	Version            string            `yaml:"version"`
+	Versions           []VersionConfig   `yaml:"versions"` // alternative to version for syncing several versions
+	Specs              []SpecConfig      `yaml:"specs"`    // specifications for the single version, see VersionConfig for several
+	Variables          map[string]string `yaml:"variables"`
+	UnknownFrontMatter string            `yaml:"unknown_front_matter"`        // error (default) or warn
+	Include            []string          `yaml:"include"`                     // globs for doc files, defaults to *.md and *.mdx
+	Exclude            []string          `yaml:"exclude"`                     // globs for files and folders to skip
+	Transforms         []string          `yaml:"transforms"`                  // markdown transforms applied before upload
+	ChangelogPath      string            `yaml:"changelog_path,omitempty"`    // folder of changelog entries, synced once per project
+	CustomPagesPath    string            `yaml:"custom_pages_path,omitempty"` // folder of custom pages, synced once per project
+	BlocksPath         string            `yaml:"blocks_path,omitempty"`       // folder of reusable blocks, synced once per project
+	Key                string            `yaml:"-"`
+	File               string            `yaml:"-"` // configuration file read, empty when there was none
+
+	lines      map[string]int // line in the configuration file of each setting, by path such as categories[1].slug
+	decodeErrs []error        // unknown keys and wrong types found while decoding the file
+}
+
+// Overrides are command line values, applied over the configuration file and environment.
+// Empty values leave the setting alone.
+type Overrides struct {
+	Path               string
+	Version            string // see SelectVersion
+	MissingCategories  string
+	UnknownFrontMatter string
+	Include            []string
+	Exclude            []string
+	Transforms         []string
+	Project            string    // see SelectProject
+	KeyReader          io.Reader // read the api key from here instead of the environment, see LoadKey
+}
+
+// NewConfig loads the layered configuration and the api key needed to sync
+func NewConfig(path string, overrides Overrides) (Config, error) {
+	cfg, err := Load(path, overrides)
+	if err != nil {
+		return Config{}, xerrors.Errorf(": %w", err)
+	}
+
+	// projects with their own key variable do not need the usual key
+	needKey := len(cfg.Projects) == 0
+	for i := range cfg.Projects {
+		project := &cfg.Projects[i]
+		if project.KeyEnv == "" {
+			needKey = true
+			continue
+		}
+		if project.Key = os.Getenv(project.KeyEnv); project.Key == "" {
+			return Config{}, xerrors.New(fmt.Sprintf("project \"%v\": %v not found in environment", project.Name, project.KeyEnv))
+		}
+	}
+	if !needKey {
+		return cfg, nil
+	}
+
+	key, err := LoadKey(overrides.KeyReader)
+	if err != nil {
+		return Config{}, xerrors.Errorf(": %w", err)
+	}
+	cfg.Key = key
+	for i := range cfg.Projects {
+		if cfg.Projects[i].KeyEnv == "" {
+			cfg.Projects[i].Key = key
+		}
+	}
+
+	return cfg, nil
 }
 
-func NewConfig(path string) (Config, error) {
+// Load layers defaults, the configuration file, environment variables and overrides, in increasing precedence.
+// The file is path, else README_SYNC_CONFIG, else .readme-sync-config.yml which may be missing.
+// The api key is not loaded.
+func Load(path string, overrides Overrides) (Config, error) {
+	cfg := Config{
+		MissingCategories:  "error",
+		UnknownFrontMatter: "error",
+	}
+
+	if path == "" {
+		path = os.Getenv(configEnv)
+	}
+	required := path != ""
 	if path == "" {
 		path = defaultConfigFile
 	}
 
-	cfgFile, err := os.Open(path)
-	if err != nil {
+	contents, err := os.ReadFile(path)
+	if err != nil && (required || !os.IsNotExist(err)) {
 		return Config{}, xerrors.Errorf(": %w", err)
 	}
+	if err := cfg.decode(contents); err != nil {
+		return Config{}, xerrors.Errorf("%v: %w", path, err)
+	}
+	if contents != nil {
+		cfg.File = path
+	}
+
+	project := firstSet(overrides.Project, os.Getenv(projectEnv))
+	if len(cfg.Projects) == 0 {
+		if project != "" {
+			return Config{}, xerrors.New(fmt.Sprintf("project \"%v\" selected but no projects are configured", project))
+		}
+		if err := cfg.resolve(overrides); err != nil {
+			return Config{}, xerrors.Errorf("%v: %w", path, err)
+		}
+		return cfg, nil
+	}
 
-	var cfg Config
-	if err := yaml.NewDecoder(cfgFile).Decode(&cfg); err != nil {
+	if err := cfg.validateProjects(); err != nil {
+		return Config{}, xerrors.Errorf("%v: %w", path, err)
+	}
+	if err := cfg.SelectProject(project); err != nil {
 		return Config{}, xerrors.Errorf(": %w", err)
 	}
+	// a docs path or version only makes sense for one project, and would otherwise rewrite or fail the rest
+	if len(cfg.Projects) > 1 && firstSet(overrides.Path, os.Getenv(pathEnv), overrides.Version, os.Getenv(versionEnv)) != "" {
+		return Config{}, xerrors.New(fmt.Sprintf("a docs path or version applies to a single project, select one with -project or %v", projectEnv))
+	}
+	for i := range cfg.Projects {
+		project := &cfg.Projects[i]
+		project.inherit(cfg, fmt.Sprintf("projects[%v].", i))
+		if err := project.resolve(overrides); err != nil {
+			return Config{}, xerrors.Errorf("%v: project \"%v\": %w", path, project.Name, err)
+		}
+	}
+	return cfg, nil
+}
 
-	// only source key from environment to prevent users from accidentally misplacing keys
-	key, ok := os.LookupEnv("README_APIKEY")
-	if !ok {
-		return Config{}, xerrors.New("README_APIKEY not found in environment")
+// resolve applies the environment and overrides over the file and validates the result
+func (cfg *Config) resolve(overrides Overrides) error {
+	// flags win over the environment, which wins over the file
+	cfg.override("path", &cfg.Path, overrides.Path, os.Getenv(pathEnv))
+	cfg.override("missing_categories", &cfg.MissingCategories, overrides.MissingCategories, os.Getenv(missingCategoriesEnv))
+	cfg.override("unknown_front_matter", &cfg.UnknownFrontMatter, overrides.UnknownFrontMatter, os.Getenv(unknownFrontMatterEnv))
+	cfg.overrideList("include", &cfg.Include, overrides.Include, os.Getenv(includeEnv))
+	cfg.overrideList("exclude", &cfg.Exclude, overrides.Exclude, os.Getenv(excludeEnv))
+	cfg.overrideList("transforms", &cfg.Transforms, overrides.Transforms, os.Getenv(transformsEnv))
+
+	// the environment wins over every layer of the file, including the variables of each version
+	if cfg.Variables == nil {
+		cfg.Variables = make(map[string]string)
 	}
-	if key == "" {
-		return Config{}, xerrors.New("README_APIKEY cannot be an empty value")
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, variableEnvPrefix) {
+			continue
+		}
+		name = strings.TrimPrefix(name, variableEnvPrefix)
+		cfg.Variables[name] = value
+		for _, v := range cfg.Versions {
+			if _, found := v.Variables[name]; found {
+				v.Variables[name] = value
+			}
+		}
 	}
-	cfg.Key = key
 
-	return cfg, nil
+	if err := cfg.SelectVersion(firstSet(overrides.Version, os.Getenv(versionEnv))); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+// inherit fills the settings the project leaves unset from the top level, and takes the
+// lines of the project's own settings from the file so errors point into the project entry
+func (p *ProjectConfig) inherit(top Config, prefix string) {
+	p.MissingCategories = firstSet(p.MissingCategories, top.MissingCategories)
+	p.UnknownFrontMatter = firstSet(p.UnknownFrontMatter, top.UnknownFrontMatter)
+	if p.Include == nil {
+		p.Include = top.Include
+	}
+	if p.Exclude == nil {
+		p.Exclude = top.Exclude
+	}
+	if p.Transforms == nil {
+		p.Transforms = top.Transforms
+	}
+
+	variables := make(map[string]string)
+	for _, layer := range []map[string]string{top.Variables, p.Variables} {
+		for name, value := range layer {
+			variables[name] = value
+		}
+	}
+	p.Variables = variables
+
+	p.lines = make(map[string]int)
+	for key, line := range top.lines {
+		if strings.HasPrefix(key, prefix) {
+			p.lines[strings.TrimPrefix(key, prefix)] = line
+		}
+	}
+}
+
+// SelectProject narrows projects down to the named one
+func (cfg *Config) SelectProject(name string) error {
+	if name == "" {
+		return nil
+	}
+	for _, p := range cfg.Projects {
+		if p.Name == name {
+			cfg.Projects = []ProjectConfig{p}
+			return nil
+		}
+	}
+	return xerrors.New(fmt.Sprintf("project \"%v\" not found in the configuration file", name))
+}
+
+// SyncProjects returns every project to sync, wrapping the top-level settings when projects is not used
+func (cfg Config) SyncProjects() []ProjectConfig {
+	if len(cfg.Projects) > 0 {
+		return cfg.Projects
+	}
+	return []ProjectConfig{{Config: cfg}}
+}
+
+func firstSet(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// override sets a setting from its flag or environment value, the first one set wins
+func (cfg *Config) override(key string, setting *string, flag, env string) {
+	if value := firstSet(flag, env); value != "" {
+		*setting = value
+		cfg.forget(key) // errors no longer point into the file
+	}
+}
+
+// overrideList is override for lists, the environment value is comma separated
+func (cfg *Config) overrideList(key string, setting *[]string, flag []string, env string) {
+	list := flag
+	if list == nil && env != "" {
+		for _, item := range strings.Split(env, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	if list != nil {
+		*setting = list
+		cfg.forget(key)
+	}
+}
+
+// SelectVersion narrows versions down to one. Without a versions list it replaces the single
+// version instead, so a sync can be redirected into e.g. a freshly forked version.
+func (cfg *Config) SelectVersion(version string) error {
+	if version == "" {
+		return nil
+	}
+	if len(cfg.Versions) == 0 {
+		cfg.Version = version
+		return nil
+	}
+	for _, v := range cfg.Versions {
+		if v.Version == version {
+			cfg.Versions = []VersionConfig{v}
+			return nil
+		}
+	}
+	return xerrors.New(fmt.Sprintf("version \"%v\" not found in the configuration file", version))
+}
+
+// Show writes the effective configuration as yaml, with the api key redacted
+func (cfg Config) Show(w io.Writer) error {
+	shown := struct {
+		Config `yaml:",inline"`
+		Key    string `yaml:"key"`
+	}{Config: cfg}
+	if cfg.Key != "" {
+		shown.Key = "REDACTED"
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(shown); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+// LoadKey returns the api key, read from stdin when it is set, else README_APIKEY, else the file named by README_APIKEY_FILE.
+// Commands that do not need the rest of the configuration use it directly.
+func LoadKey(stdin io.Reader) (string, error) {
+	// never source the key from the configuration file, to prevent users from accidentally committing keys
+	if stdin != nil {
+		contents, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", xerrors.Errorf(": %w", err)
+		}
+		key := strings.TrimSpace(string(contents))
+		if key == "" {
+			return "", xerrors.New("no api key on stdin")
+		}
+		return key, nil
+	}
+
+	if key, ok := os.LookupEnv(keyEnv); ok {
+		if key == "" {
+			return "", xerrors.New(keyEnv + " cannot be an empty value")
+		}
+		return key, nil
+	}
+
+	if path := os.Getenv(keyFileEnv); path != "" {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return "", xerrors.Errorf("%v: %w", keyFileEnv, err)
+		}
+		key := strings.TrimSpace(string(contents))
+		if key == "" {
+			return "", xerrors.New(fmt.Sprintf("%v: %v is empty", keyFileEnv, path))
+		}
+		return key, nil
+	}
+
+	return "", xerrors.New(keyEnv + " or " + keyFileEnv + " not found in environment")
+}
+
+// Targets returns every version to sync, wrapping the single version setting when versions is not used
+func (cfg Config) Targets() []VersionConfig {
+	if len(cfg.Versions) > 0 {
+		return cfg.Versions
+	}
+	return []VersionConfig{{Version: cfg.Version, Specs: cfg.Specs}}
 }
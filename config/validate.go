@@ -0,0 +1,252 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gosimple/slug"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+// FieldError is a single problem with a setting
+type FieldError struct {
+	Path string // such as categories[1].slug
+	Line int    // line in the configuration file, 0 when the setting did not come from it
+	Msg  string
+}
+
+func (e FieldError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %v: %v: %v", e.Line, e.Path, e.Msg)
+	}
+	return fmt.Sprintf("%v: %v", e.Path, e.Msg)
+}
+
+// ValidationError lists every problem found, so a configuration can be fixed in one go
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return "invalid configuration:\n  " + strings.Join(msgs, "\n  ")
+}
+
+// decode strictly decodes the configuration file and remembers where each setting is.
+// Unknown keys and wrong types are kept for Validate.
+func (cfg *Config) decode(contents []byte) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(contents, &root); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	cfg.lines = make(map[string]int)
+	recordLines(&root, "", cfg.lines)
+
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	err := decoder.Decode(cfg)
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		// the rest of the file still decodes, so these are reported by Validate alongside everything else
+		for _, msg := range typeErr.Errors {
+			cfg.decodeErrs = append(cfg.decodeErrs, errors.New(msg))
+		}
+		return nil
+	}
+	if err != nil && err != io.EOF {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+func recordLines(node *yaml.Node, path string, lines map[string]int) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			recordLines(child, path, lines)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if path != "" {
+				key = path + "." + key
+			}
+			lines[key] = node.Content[i].Line
+			recordLines(node.Content[i+1], key, lines)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			key := fmt.Sprintf("%v[%v]", path, i)
+			lines[key] = child.Line
+			recordLines(child, key, lines)
+		}
+	}
+}
+
+// forget drops the lines of a setting and everything below it
+func (cfg *Config) forget(path string) {
+	for key := range cfg.lines {
+		if key == path || strings.HasPrefix(key, path+".") || strings.HasPrefix(key, path+"[") {
+			delete(cfg.lines, key)
+		}
+	}
+}
+
+// line finds the line of a setting, or of its closest parent when the setting itself was not written
+func (cfg *Config) line(path string) int {
+	for path != "" {
+		if line, ok := cfg.lines[path]; ok {
+			return line
+		}
+		cut := strings.LastIndexAny(path, ".[")
+		if cut < 0 {
+			break
+		}
+		path = path[:cut]
+	}
+	return 0
+}
+
+// Validate checks the whole configuration and returns a *ValidationError listing every problem
+func (cfg *Config) Validate() error {
+	errs := append([]error{}, cfg.decodeErrs...)
+	fail := func(path, format string, args ...any) {
+		errs = append(errs, FieldError{Path: path, Line: cfg.line(path), Msg: fmt.Sprintf(format, args...)})
+	}
+
+	seenCategories := make(map[string]int)
+	for i, cat := range cfg.Categories {
+		path := fmt.Sprintf("categories[%v]", i)
+		switch {
+		case cat.Slug == "":
+			fail(path+".slug", "is required")
+		case !slug.IsSlug(cat.Slug):
+			fail(path+".slug", "%q is not a slug, use lowercase letters, digits and dashes", cat.Slug)
+		}
+		if first, dup := seenCategories[cat.Slug]; dup && cat.Slug != "" {
+			fail(path+".slug", "duplicates categories[%v]", first)
+		} else {
+			seenCategories[cat.Slug] = i
+		}
+		switch cat.Type {
+		case "", "guide", "reference":
+		default:
+			fail(path+".type", "must be guide or reference")
+		}
+		if cat.Order != nil && *cat.Order < 0 {
+			fail(path+".order", "cannot be negative")
+		}
+	}
+
+	switch {
+	case cfg.Version != "" && len(cfg.Versions) > 0:
+		fail("versions", "only one of version and versions can be set")
+	case cfg.Version == "" && len(cfg.Versions) == 0:
+		fail("version", "version or versions is required")
+	}
+	if len(cfg.Specs) > 0 && len(cfg.Versions) > 0 {
+		fail("specs", "must be set per version when versions is used")
+	}
+	validateSpecs := func(path string, specs []SpecConfig) {
+		for i, spec := range specs {
+			if spec.Path == "" {
+				fail(fmt.Sprintf("%v[%v].path", path, i), "is required")
+			}
+		}
+	}
+	validateSpecs("specs", cfg.Specs)
+
+	seenVersions := make(map[string]int)
+	for i, v := range cfg.Versions {
+		path := fmt.Sprintf("versions[%v]", i)
+		if v.Version == "" {
+			fail(path+".version", "is required")
+		} else if first, dup := seenVersions[v.Version]; dup {
+			fail(path+".version", "duplicates versions[%v]", first)
+		} else {
+			seenVersions[v.Version] = i
+		}
+		validateSpecs(path+".specs", v.Specs)
+	}
+
+	switch cfg.MissingCategories {
+	case "", "error", "slug":
+	default:
+		fail("missing_categories", "must be error or slug")
+	}
+
+	switch cfg.UnknownFrontMatter {
+	case "", "error", "warn":
+	default:
+		fail("unknown_front_matter", "must be error or warn")
+	}
+
+	for _, list := range []struct {
+		key   string
+		items []string
+	}{{"include", cfg.Include}, {"exclude", cfg.Exclude}, {"transforms", cfg.Transforms}} {
+		for i, item := range list.items {
+			if strings.TrimSpace(item) == "" {
+				fail(fmt.Sprintf("%v[%v]", list.key, i), "cannot be empty")
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// validateProjects checks the parts of a configuration with projects that are not validated per project
+func (cfg *Config) validateProjects() error {
+	errs := append([]error{}, cfg.decodeErrs...)
+	fail := func(path, format string, args ...any) {
+		errs = append(errs, FieldError{Path: path, Line: cfg.line(path), Msg: fmt.Sprintf(format, args...)})
+	}
+
+	for _, set := range []struct {
+		key string
+		set bool
+	}{
+		{"path", cfg.Path != ""},
+		{"categories", len(cfg.Categories) > 0},
+		{"version", cfg.Version != ""},
+		{"versions", len(cfg.Versions) > 0},
+		{"specs", len(cfg.Specs) > 0},
+		{"changelog_path", cfg.ChangelogPath != ""},
+		{"custom_pages_path", cfg.CustomPagesPath != ""},
+		{"blocks_path", cfg.BlocksPath != ""},
+	} {
+		if set.set {
+			fail(set.key, "must be set per project when projects is used")
+		}
+	}
+
+	seen := make(map[string]int)
+	for i, p := range cfg.Projects {
+		path := fmt.Sprintf("projects[%v]", i)
+		if p.Name == "" {
+			fail(path+".name", "is required")
+		} else if first, dup := seen[p.Name]; dup {
+			fail(path+".name", "duplicates projects[%v]", first)
+		} else {
+			seen[p.Name] = i
+		}
+		if len(p.Projects) > 0 {
+			fail(path+".projects", "projects cannot be nested")
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string // every problem, in order
+	}{
+		{
+			name:   "valid",
+			config: "path: docs\nversion: v1\ncategories:\n  - slug: guides\n",
+		},
+		{
+			name:   "unknown key",
+			config: "path: docs\nversion: v1\ncategory:\n  - slug: guides\n",
+			want:   []string{"line 3: field category not found in type config.Config"},
+		},
+		{
+			name:   "wrong type",
+			config: "path: docs\nversion: v1\ncategories:\n  - slug: guides\n    order: first\n",
+			want:   []string{"line 5: cannot unmarshal !!str `first` into int"},
+		},
+		{
+			name:   "duplicate category slugs",
+			config: "path: docs\nversion: v1\ncategories:\n  - slug: guides\n  - slug: api\n  - slug: guides\n",
+			want:   []string{"line 6: categories[2].slug: duplicates categories[0]"},
+		},
+		{
+			name:   "duplicate versions",
+			config: "path: docs\nversions:\n  - version: v1\n  - version: v2\n  - version: v1\n",
+			want:   []string{"line 5: versions[2].version: duplicates versions[0]"},
+		},
+		{
+			name:   "missing version",
+			config: "path: docs\n",
+			want:   []string{"version: version or versions is required"},
+		},
+		{
+			name: "several problems together",
+			config: `path: docs
+version: v1
+missing_categories: ignore
+categories:
+  - slug: Guides
+    type: tutorial
+  - title: Untitled
+unknown: true
+`,
+			want: []string{
+				"line 8: field unknown not found in type config.Config",
+				`line 5: categories[0].slug: "Guides" is not a slug, use lowercase letters, digits and dashes`,
+				"line 6: categories[0].type: must be guide or reference",
+				"line 7: categories[1].slug: is required",
+				"line 3: missing_categories: must be error or slug",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yml")
+			if err := os.WriteFile(path, []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Load(path, Overrides{})
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Load: %v", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("error = %v, want a *ValidationError", err)
+			}
+			var got []string
+			for _, problem := range validationErr.Errors {
+				got = append(got, problem.Error())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("problems:\n  %v\nwant:\n  %v", strings.Join(got, "\n  "), strings.Join(tt.want, "\n  "))
+			}
+			for i := range got {
+				if !strings.Contains(got[i], tt.want[i]) {
+					t.Errorf("problem %v = %q, want it to contain %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rolflewis/readme-sync/config"
+	"golang.org/x/xerrors"
+)
+
+// listFlags collects a repeatable flag, each value may also be comma separated
+type listFlags []string
+
+func (l *listFlags) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlags) Set(raw string) error {
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// configFlags are the flags overriding the configuration file and environment
+type configFlags struct {
+	path          string
+	flagOverrides config.Overrides
+	include       listFlags
+	exclude       listFlags
+	transforms    listFlags
+}
+
+func (c *configFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.path, "config", "", "path to the configuration file, defaults to README_SYNC_CONFIG or .readme-sync-config.yml")
+	fs.StringVar(&c.flagOverrides.Path, "path", "", "path to docs root")
+	fs.StringVar(&c.flagOverrides.Project, "project", "", "only sync this project from the configuration file")
+	fs.StringVar(&c.flagOverrides.Version, "version", "", "only sync this version from the configuration file, or replace the single configured version")
+	fs.StringVar(&c.flagOverrides.MissingCategories, "missing-categories", "", "error or slug, see missing_categories")
+	fs.StringVar(&c.flagOverrides.UnknownFrontMatter, "unknown-front-matter", "", "error or warn, see unknown_front_matter")
+	fs.Var(&c.include, "include", "glob for doc files, replaces include (repeatable)")
+	fs.Var(&c.exclude, "exclude", "glob for files and folders to skip, replaces exclude (repeatable)")
+	fs.Var(&c.transforms, "transform", "markdown transform, replaces transforms (repeatable)")
+}
+
+// only lists that were given replace the file's, an empty list would otherwise clear them
+func (c *configFlags) overrides() config.Overrides {
+	overrides := c.flagOverrides
+	overrides.Include, overrides.Exclude, overrides.Transforms = c.include, c.exclude, c.transforms
+	return overrides
+}
+
+// load returns the layered configuration, with the api key read from keyReader or the environment
+func (c *configFlags) load(keyReader io.Reader) (config.Config, error) {
+	overrides := c.overrides()
+	overrides.KeyReader = keyReader
+
+	cfg, err := config.NewConfig(c.path, overrides)
+	if err != nil {
+		return config.Config{}, xerrors.Errorf(": %w", err)
+	}
+	return cfg, nil
+}
+
+// configCmd inspects the configuration that walk would use with the same flags
+func configCmd(ctx context.Context, lf *logFlags, args []string) error {
+	if len(args) < 1 || args[0] != "show" {
+		return xerrors.New("config requires show")
+	}
+
+	fs := flag.NewFlagSet("config show", flag.ContinueOnError)
+	cf := &configFlags{}
+	cf.register(fs)
+	ef := &envFlags{}
+	ef.register(fs)
+	lf.register(fs)
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	if _, err := lf.apply(ctx); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+
+	if err := ef.load(); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+
+	cfg, err := config.Load(cf.path, cf.overrides())
+	if err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	if key, err := ef.key(); err == nil {
+		cfg.Key = key // only shown as set or not
+	}
+
+	if err := cfg.Show(os.Stdout); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Change is a single field that differs between the remote and local version of an item
+type Change struct {
+	Field string // json name, dotted for nested structs
+	Old   any
+	New   any
+}
+
+// Fields compares two values of the same struct type field by field, naming fields by their json tags.
+// The id and any field named in skip are left out, so long text can be shown with Unified instead.
+// Nil and empty slices are considered equal, as readme does not distinguish them.
+func Fields(old, new any, skip ...string) []Change {
+	var changes []Change
+	compareFields(reflect.ValueOf(old), reflect.ValueOf(new), "", skip, &changes)
+	return changes
+}
+
+func compareFields(old, new reflect.Value, prefix string, skip []string, changes *[]Change) {
+	for i := 0; i < old.NumField(); i++ {
+		field := old.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		if name == "-" || name == "_id" || !field.IsExported() || contains(skip, prefix+name) {
+			continue
+		}
+
+		o, n := old.Field(i), new.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			compareFields(o, n, prefix+name+".", skip, changes)
+			continue
+		}
+		if !equal(o, n) {
+			*changes = append(*changes, Change{Field: prefix + name, Old: display(o), New: display(n)})
+		}
+	}
+}
+
+func equal(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Slice && a.Len() == 0 && b.Len() == 0 {
+		return true
+	}
+	if a.Kind() == reflect.Pointer {
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return reflect.DeepEqual(a.Elem().Interface(), b.Elem().Interface())
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func display(v reflect.Value) any {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		return v.Elem().Interface()
+	}
+	return v.Interface()
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	red   = "\x1b[31m"
+	green = "\x1b[32m"
+	cyan  = "\x1b[36m"
+	reset = "\x1b[0m"
+)
+
+// Format renders field changes followed by a unified diff, colorizing removed, added and hunk lines when color is set
+func Format(changes []Change, unified string, color bool) string {
+	var lines []string
+	for _, change := range changes {
+		lines = append(lines,
+			fmt.Sprintf("-%v: %v", change.Field, show(change.Old)),
+			fmt.Sprintf("+%v: %v", change.Field, show(change.New)),
+		)
+	}
+	if unified != "" {
+		lines = append(lines, strings.Split(strings.TrimSuffix(unified, "\n"), "\n")...)
+	}
+
+	if color {
+		for i, line := range lines {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				lines[i] = cyan + line + reset
+			case strings.HasPrefix(line, "-"):
+				lines[i] = red + line + reset
+			case strings.HasPrefix(line, "+"):
+				lines[i] = green + line + reset
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func show(v any) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
+// Describe renders how new differs from old: changed fields first, then a unified diff
+// of each named top-level text field such as a markdown body
+func Describe(old, new any, textFields []string, color bool) string {
+	var unified []string
+	for _, name := range textFields {
+		oldText, newText := textField(old, name), textField(new, name)
+		if d := Unified("remote/"+name, "local/"+name, oldText, newText, 3); d != "" {
+			unified = append(unified, d)
+		}
+	}
+	return Format(Fields(old, new, textFields...), strings.Join(unified, ""), color)
+}
+
+func textField(v any, name string) string {
+	value := reflect.ValueOf(v)
+	for i := 0; i < value.NumField(); i++ {
+		if tag, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ","); tag == name {
+			return value.Field(i).String()
+		}
+	}
+	return ""
+}
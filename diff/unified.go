@@ -0,0 +1,207 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// past this many line pairs the changed region is shown as one replacement instead of being aligned
+const maxCompare = 4_000_000
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind     opKind
+	line     string
+	old, new int // line numbers before and after, from 1
+}
+
+// Unified returns a unified diff of two texts with the given lines of context,
+// or an empty string when they are equal
+func Unified(oldName, newName, old, new string, context int) string {
+	if old == new {
+		return ""
+	}
+	ops := lineOps(splitLines(old), splitLines(new))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %v\n+++ %v\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		// find the next change
+		for start < len(ops) && ops[start].kind == opEqual {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// extend the hunk while changes are closer than twice the context
+		from := start - context
+		if from < 0 {
+			from = 0
+		}
+		end := start
+		for end < len(ops) {
+			next := end
+			for next < len(ops) && ops[next].kind == opEqual {
+				next++
+			}
+			if next == len(ops) || next-end > 2*context {
+				break
+			}
+			for next < len(ops) && ops[next].kind != opEqual {
+				next++
+			}
+			end = next
+		}
+		to := end + context
+		if to > len(ops) {
+			to = len(ops)
+		}
+
+		oldStart, newStart, oldCount, newCount := ops[from].old, ops[from].new, 0, 0
+		for _, o := range ops[from:to] {
+			if o.kind != opInsert {
+				oldCount++
+			}
+			if o.kind != opDelete {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%v +%v @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		for _, o := range ops[from:to] {
+			b.WriteString(string(o.kind) + o.line + "\n")
+		}
+		start = to
+	}
+	return b.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%v,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%v,%v", start, count)
+}
+
+// noNewline follows a last line without a line break, so that it differs from the same line with one
+const noNewline = "\n\\ No newline at end of file"
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	if !strings.HasSuffix(s, "\n") {
+		lines[len(lines)-1] += noNewline
+	}
+	return lines
+}
+
+// lineOps aligns the lines of a and b through their longest common subsequence,
+// after trimming the common prefix and suffix which is where most edits leave docs untouched
+func lineOps(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	var kinds []opKind
+	if len(midA)*len(midB) > maxCompare {
+		for range midA {
+			kinds = append(kinds, opDelete)
+		}
+		for range midB {
+			kinds = append(kinds, opInsert)
+		}
+	} else {
+		kinds = lcsOps(midA, midB)
+	}
+
+	var ops []op
+	i, j := 0, 0
+	emit := func(kind opKind) {
+		o := op{kind: kind, old: i + 1, new: j + 1}
+		switch kind {
+		case opEqual:
+			o.line = a[i]
+			i++
+			j++
+		case opDelete:
+			o.line = a[i]
+			i++
+		case opInsert:
+			o.line = b[j]
+			j++
+		}
+		ops = append(ops, o)
+	}
+	for k := 0; k < prefix; k++ {
+		emit(opEqual)
+	}
+	for _, kind := range kinds {
+		emit(kind)
+	}
+	for k := 0; k < suffix; k++ {
+		emit(opEqual)
+	}
+	return ops
+}
+
+func lcsOps(a, b []string) []opKind {
+	// lengths[i][j] is the lcs length of a[i:] and b[j:]
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = lengths[i+1][j]
+				if lengths[i][j+1] > lengths[i][j] {
+					lengths[i][j] = lengths[i][j+1]
+				}
+			}
+		}
+	}
+
+	var kinds []opKind
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			kinds = append(kinds, opEqual)
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			kinds = append(kinds, opDelete)
+			i++
+		default:
+			kinds = append(kinds, opInsert)
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		kinds = append(kinds, opDelete)
+	}
+	for ; j < len(b); j++ {
+		kinds = append(kinds, opInsert)
+	}
+	return kinds
+}
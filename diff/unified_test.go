@@ -0,0 +1,111 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		context int
+		want    string
+	}{
+		{
+			name: "identical",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		{
+			name:    "insert at the start",
+			old:     "b\nc\n",
+			new:     "a\nb\nc\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1 +1,2 @@\n+a\n b\n",
+		},
+		{
+			name:    "insert at the end",
+			old:     "a\nb\n",
+			new:     "a\nb\nc\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -2 +2,2 @@\n b\n+c\n",
+		},
+		{
+			name:    "delete at the start",
+			old:     "a\nb\nc\n",
+			new:     "b\nc\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,2 +1 @@\n-a\n b\n",
+		},
+		{
+			name:    "delete at the end",
+			old:     "a\nb\nc\n",
+			new:     "a\nb\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -2,2 +2 @@\n b\n-c\n",
+		},
+		{
+			name:    "from empty",
+			old:     "",
+			new:     "a\n",
+			context: 3,
+			want:    "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:    "to empty",
+			old:     "a\n",
+			new:     "",
+			context: 3,
+			want:    "--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			name:    "replace in the middle",
+			old:     "a\nb\nc\n",
+			new:     "a\nx\nc\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name:    "only the trailing newline is missing",
+			old:     "a\nb\n",
+			new:     "a\nb",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			name:    "trailing newline added",
+			old:     "a",
+			new:     "a\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n",
+		},
+		{
+			name:    "unchanged last line without newline",
+			old:     "a\nb",
+			new:     "x\nb",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,2 +1,2 @@\n-a\n+x\n b\n\\ No newline at end of file\n",
+		},
+		{
+			name:    "distant changes make separate hunks",
+			old:     "a\n1\n2\n3\n4\nb\n",
+			new:     "A\n1\n2\n3\n4\nB\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,2 +1,2 @@\n-a\n+A\n 1\n@@ -5,2 +5,2 @@\n 4\n-b\n+B\n",
+		},
+		{
+			name:    "close changes share a hunk",
+			old:     "a\n1\n2\nb\n",
+			new:     "A\n1\n2\nB\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n-b\n+B\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("old", "new", tt.old, tt.new, tt.context); got != tt.want {
+				t.Errorf("Unified(%q, %q) =\n%v\nwant\n%v", tt.old, tt.new, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gosimple/slug"
+	"github.com/rolflewis/readme-sync/logging"
+	"github.com/rolflewis/readme-sync/readme"
+	"github.com/rolflewis/readme-sync/report"
+	"golang.org/x/xerrors"
+)
+
+// matches embedded blocks, whose json payload names the tag:
+// [block:custom-block]
+// {"tag": "auth-warning"}
+// [/block]
+var blockRefPattern = regexp.MustCompile(`(?s)\[block:custom-block\](.*?)\[/block\]`)
+
+// BlockMetadata locates a reusable block, tagged by its file name
+type BlockMetadata struct {
+	Tag      string
+	Filepath string
+	Roots    []string
+}
+
+type blockFrontMatter struct {
+	Name string `yaml:"name"` // defaults to the tag
+	Html bool   `yaml:"html"` // the body is html rather than markdown
+}
+
+// blockReferences returns the tags of the blocks embedded in a markdown body, leaving out
+// the ones in code spans and blocks, which show the syntax rather than embed a block
+func blockReferences(body []byte) ([]string, error) {
+	skip := codeRanges(body)
+	var tags []string
+	for _, match := range blockRefPattern.FindAllSubmatchIndex(body, -1) {
+		if inRanges(skip, match[0]) {
+			continue
+		}
+		var payload struct {
+			Tag string `json:"tag"`
+		}
+		if err := json.Unmarshal(body[match[2]:match[3]], &payload); err != nil {
+			return nil, xerrors.Errorf("embedded block: %w", err)
+		}
+		if payload.Tag == "" {
+			return nil, xerrors.New("embedded block has no tag")
+		}
+		tags = append(tags, payload.Tag)
+	}
+	return tags, nil
+}
+
+func walkBlocks(folder string, skip func(string, bool) (bool, error)) (map[string]BlockMetadata, error) {
+	roots := []string{folder}
+	files, err := readLayeredDir(roots, "")
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+
+	blocks := make(map[string]BlockMetadata)
+	for _, file := range files {
+		if isPartialName(file.Name()) {
+			continue
+		}
+		if skipped, err := skip(file.Name(), file.IsDir()); err != nil {
+			return nil, xerrors.Errorf(": %w", err)
+		} else if skipped {
+			continue
+		}
+		if file.IsDir() {
+			return nil, xerrors.New(folder + ": cannot contain folders")
+		}
+
+		tag := slug.Make(strings.TrimSuffix(file.Name(), filepath.Ext(file.Name())))
+		if _, dup := blocks[tag]; dup {
+			return nil, xerrors.New("duplicate block tag: " + tag)
+		}
+		blocks[tag] = BlockMetadata{
+			Tag:      tag,
+			Filepath: file.Path,
+			Roots:    roots,
+		}
+	}
+	return blocks, nil
+}
+
+// ReferencesBlocks reports whether any doc embeds a block
+func (c Catalog) ReferencesBlocks() bool {
+	for _, doc := range c.Docs {
+		if len(doc.Blocks) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateBlockReferences checks that every block embedded in a doc is one of the known tags
+func (c Catalog) ValidateBlockReferences(known map[string]struct{}) error {
+	refs := make(map[string][]string)
+	for slug, doc := range c.Docs {
+		refs[fmt.Sprintf("doc with slug \"%v\"", slug)] = doc.Blocks
+	}
+	return validateBlockReferences(refs, known)
+}
+
+// ReferencesBlocks reports whether any changelog entry or custom page embeds a block
+func (s Shared) ReferencesBlocks() bool {
+	for _, entries := range []map[string]EntryMetadata{s.Changelogs, s.CustomPages} {
+		for _, entry := range entries {
+			if len(entry.Blocks) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValidateBlockReferences checks that every block embedded in a changelog entry or custom page is one of the known tags
+func (s Shared) ValidateBlockReferences(known map[string]struct{}) error {
+	refs := make(map[string][]string)
+	for slug, changelog := range s.Changelogs {
+		refs[fmt.Sprintf("changelog with slug \"%v\"", slug)] = changelog.Blocks
+	}
+	for slug, page := range s.CustomPages {
+		refs[fmt.Sprintf("custom page with slug \"%v\"", slug)] = page.Blocks
+	}
+	return validateBlockReferences(refs, known)
+}
+
+// validateBlockReferences checks the tags embedded by each described item
+func validateBlockReferences(refs map[string][]string, known map[string]struct{}) error {
+	var problems []string
+	for item, tags := range refs {
+		for _, tag := range tags {
+			if _, found := known[tag]; !found {
+				problems = append(problems, fmt.Sprintf("%v references unknown block \"%v\"", item, tag))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return xerrors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+func ProcessBlock(ctx context.Context, c *readme.Client, metadata BlockMetadata, opts DocOptions) (report.Action, error) {
+	log := logging.FromContext(ctx)
+
+	f, err := os.Open(metadata.Filepath)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+	defer f.Close()
+
+	var matter blockFrontMatter
+	rest, warnings, err := parseFrontMatter(f, &matter, opts.UnknownKeys)
+	if err != nil {
+		return "", xerrors.Errorf("block with tag \"%v\": %w", metadata.Tag, err)
+	}
+	for _, warning := range warnings {
+		log.Warn(warning, "block", metadata.Tag)
+	}
+
+	block := readme.Block{
+		Tag:  metadata.Tag,
+		Name: matter.Name,
+		Type: "markdown",
+	}
+	if block.Name == "" {
+		block.Name = metadata.Tag
+	}
+	if matter.Html {
+		block.Type = "html"
+	}
+	if block.Body, err = renderBody(metadata.Roots, rest, opts, matter.Html); err != nil {
+		return "", xerrors.Errorf("block with tag \"%v\": %w", metadata.Tag, err)
+	}
+
+	existing, err := c.GetBlock(ctx, block.Tag)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+
+	var action report.Action
+	if existing.Id == "" {
+		action = report.Created
+		log.Info("Creating block", "tag", block.Tag)
+		if err := c.CreateBlock(ctx, block); err != nil {
+			return "", xerrors.Errorf(": %w", err)
+		}
+	} else if existing.Id = ""; !sameBlock(existing, block) {
+		action = report.Updated
+		log.Info("Updating block", append([]any{"tag", block.Tag}, changeAttrs(opts, canonicalBlock(existing, matter.Html), canonicalBlock(block, matter.Html), "body")...)...)
+		if err := c.UpdateBlock(ctx, block); err != nil {
+			return "", xerrors.Errorf(": %w", err)
+		}
+	} else {
+		action = report.Unchanged
+		log.Info("No change to block", "tag", block.Tag)
+	}
+	return action, nil
+}
+
+func canonicalBlock(block readme.Block, html bool) readme.Block {
+	if html {
+		block.Body = normalizeText(block.Body)
+	} else {
+		block.Body = normalizeMarkdown(block.Body)
+	}
+	return block
+}
+
+func sameBlock(existing, local readme.Block) bool {
+	html := local.Type == "html"
+	return canonicalBlock(existing, html) == canonicalBlock(local, html)
+}
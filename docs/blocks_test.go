@@ -0,0 +1,98 @@
+package docs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBlockReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "usual layout",
+			body: "Text\n\n[block:custom-block]\n{\"tag\": \"auth-warning\"}\n[/block]\n",
+			want: []string{"auth-warning"},
+		},
+		{
+			name: "compact",
+			body: `[block:custom-block]{"tag":"a"}[/block] and [block:custom-block]{"tag":"b"}[/block]`,
+			want: []string{"a", "b"},
+		},
+		{
+			name: "other keys and spacing",
+			body: "[block:custom-block]\n{\n  \"name\": \"Auth warning\",\n  \"tag\" : \"auth-warning\"\n}\n[/block]\n",
+			want: []string{"auth-warning"},
+		},
+		{
+			name: "other block types are ignored",
+			body: "[block:callout]\n{\"type\": \"info\"}\n[/block]\n",
+		},
+		{
+			name: "fenced code shows the syntax",
+			body: "```\n[block:custom-block]\n{\"tag\": \"example\"}\n[/block]\n```\n",
+		},
+		{
+			name: "code span shows the syntax",
+			body: "Write `[block:custom-block]{\"tag\": \"example\"}[/block]` to embed a block.\n",
+		},
+		{
+			name:    "invalid payload",
+			body:    "[block:custom-block]\n{tag: auth-warning}\n[/block]\n",
+			wantErr: "embedded block",
+		},
+		{
+			name:    "missing tag",
+			body:    "[block:custom-block]\n{\"name\": \"Auth warning\"}\n[/block]\n",
+			wantErr: "has no tag",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := blockReferences([]byte(tt.body))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("blockReferences: %v", err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBlockReferences(t *testing.T) {
+	known := map[string]struct{}{"auth-warning": {}}
+	catalog := Catalog{Docs: map[string]DocMetadata{
+		"intro": {Blocks: []string{"auth-warning"}},
+		"setup": {Blocks: []string{"missing", "auth-warning"}},
+	}}
+	shared := Shared{
+		Changelogs:  map[string]EntryMetadata{"release": {Blocks: []string{"gone"}}},
+		CustomPages: map[string]EntryMetadata{"about": {}},
+	}
+
+	if !catalog.ReferencesBlocks() || !shared.ReferencesBlocks() {
+		t.Error("ReferencesBlocks = false, want true")
+	}
+	if (Shared{CustomPages: map[string]EntryMetadata{"about": {}}}).ReferencesBlocks() {
+		t.Error("ReferencesBlocks = true without any embedded block")
+	}
+
+	err := catalog.ValidateBlockReferences(known)
+	if err == nil || err.Error() != `doc with slug "setup" references unknown block "missing"` {
+		t.Errorf("catalog error = %v", err)
+	}
+	err = shared.ValidateBlockReferences(known)
+	if err == nil || err.Error() != `changelog with slug "release" references unknown block "gone"` {
+		t.Errorf("shared error = %v", err)
+	}
+}
@@ -1,46 +1,119 @@
 package docs
 
 import (
+	"bytes"
 	"context"
-	"fmt"
-	"log"
+	"io"
+	"os"
 
-	"github.com/google/go-cmp/cmp"
+	"github.com/rolflewis/readme-sync/logging"
 	"github.com/rolflewis/readme-sync/readme"
+	"github.com/rolflewis/readme-sync/report"
 	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
 )
 
+// name of the optional settings file inside a category folder
+const categoryFile = "_category.yml"
+
+// CategoryFile holds the settings read from a category folder's _category.yml.
+// Entries in the root configuration file take precedence over it.
+type CategoryFile struct {
+	Title    string `yaml:"title"`
+	Type     string `yaml:"type"`
+	Order    *int   `yaml:"order"`
+	FromFile bool   `yaml:"-"` // false when the folder has no category file
+}
+
+func readCategoryFile(path string) (CategoryFile, error) {
+	contents, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return CategoryFile{}, nil
+	}
+	if err != nil {
+		return CategoryFile{}, xerrors.Errorf(": %w", err)
+	}
+
+	var file CategoryFile
+	dec := yaml.NewDecoder(bytes.NewReader(contents))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && err != io.EOF {
+		return CategoryFile{}, xerrors.Errorf("%v: %w", path, err)
+	}
+
+	switch file.Type {
+	case "", "guide", "reference":
+	default:
+		return CategoryFile{}, xerrors.New(path + ": type must be guide or reference")
+	}
+	file.FromFile = true
+	return file, nil
+}
+
 type CatMetadata struct {
 	Title string
 	Slug  string
+	Type  string
+	Order int
 }
 
-// TODO: improve tracking of final category slug - may need to pass it back
-func ProcessCategory(ctx context.Context, c *readme.Client, metadata CatMetadata) error {
-	existing, err := c.GetCategory(ctx, metadata.Slug)
+// CheckNewCategories makes sure the categories missing from readme can be created under their slugs
+func CheckNewCategories(ctx context.Context, c *readme.Client, categories []CatMetadata) error {
+	existing, err := c.GetCategories(ctx)
 	if err != nil {
 		return xerrors.Errorf(": %w", err)
 	}
+	slugs := make(map[string]struct{})
+	for _, cat := range existing {
+		slugs[cat.Slug] = struct{}{}
+	}
+
+	var created []readme.Category
+	for _, metadata := range categories {
+		if _, found := slugs[metadata.Slug]; !found {
+			created = append(created, readme.Category{Slug: metadata.Slug, Title: metadata.Title})
+		}
+	}
+	if err := readme.CheckNewCategories(existing, created); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+func ProcessCategory(ctx context.Context, c *readme.Client, metadata CatMetadata, opts DocOptions) (report.Action, error) {
+	log := logging.FromContext(ctx)
+
+	existing, err := c.GetCategory(ctx, metadata.Slug)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
 
 	cat := readme.Category{
 		Title: metadata.Title,
 		Slug:  metadata.Slug,
+		Type:  metadata.Type,
+		Order: metadata.Order,
+	}
+	if cat.Type == "" {
+		cat.Type = "guide"
 	}
 
+	var action report.Action
 	if existing == (readme.Category{}) {
-		fmt.Printf("Creating category with slug \"%v\"\n", cat.Slug)
-		if err := c.CreateCategory(ctx, cat); err != nil {
-			return xerrors.Errorf(": %w", err)
+		log.Info("Creating category", "slug", cat.Slug)
+		if _, err := c.CreateCategory(ctx, cat); err != nil {
+			return "", xerrors.Errorf("category folder \"%v\": %w", metadata.Slug, err)
 		}
-		return nil
+		return report.Created, nil
 	} else if cat.Id = existing.Id; existing != cat {
-		log.Println(cmp.Diff(existing, cat))
-		fmt.Printf("Updating category with slug \"%v\"\n", cat.Slug)
+		action = report.Updated
+		log.Info("Updating category", append([]any{"slug", cat.Slug}, changeAttrs(opts, existing, cat)...)...)
 		if err := c.UpdateCategory(ctx, cat); err != nil {
-			return xerrors.Errorf(": %w", err)
+			return "", xerrors.Errorf(": %w", err)
 		}
 	} else {
-		fmt.Printf("No change to category with slug \"%v\"\n", cat.Slug)
+		action = report.Unchanged
+		log.Info("No change to category", "slug", cat.Slug)
 	}
-	return nil
+	return action, nil
 }
@@ -0,0 +1,135 @@
+package docs
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/rolflewis/readme-sync/logging"
+	"github.com/rolflewis/readme-sync/readme"
+	"github.com/rolflewis/readme-sync/report"
+	"golang.org/x/xerrors"
+)
+
+type changelogFrontMatter struct {
+	Title  string `yaml:"title"`
+	Type   string `yaml:"type"`
+	Hidden bool   `yaml:"hidden"`
+	Date   string `yaml:"date"` // YYYY-MM-DD or RFC 3339
+}
+
+func (fm *changelogFrontMatter) validate() error {
+	if fm.Title == "" {
+		return xerrors.New("title is required")
+	}
+	if fm.Type != "" {
+		var known bool
+		for _, t := range readme.ChangelogTypes {
+			known = known || t == fm.Type
+		}
+		if !known {
+			return xerrors.New("type must be one of added, fixed, improved, deprecated or removed")
+		}
+	}
+	if _, err := fm.date(); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+func (fm *changelogFrontMatter) date() (time.Time, error) {
+	if fm.Date == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, fm.Date); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, xerrors.New("date must be YYYY-MM-DD or RFC 3339")
+}
+
+func ProcessChangelog(ctx context.Context, c *readme.Client, metadata EntryMetadata, opts DocOptions) (report.Action, error) {
+	log := logging.FromContext(ctx)
+
+	f, err := os.Open(metadata.Filepath)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+	defer f.Close()
+
+	var matter changelogFrontMatter
+	rest, warnings, err := parseFrontMatter(f, &matter, opts.UnknownKeys)
+	if err != nil {
+		return "", xerrors.Errorf("changelog with slug \"%v\": %w", metadata.Slug, err)
+	}
+	for _, warning := range warnings {
+		log.Warn(warning, "changelog", metadata.Slug)
+	}
+
+	if err := matter.validate(); err != nil {
+		return "", xerrors.Errorf("changelog with slug \"%v\": %w", metadata.Slug, err)
+	}
+
+	body, err := renderBody(metadata.Roots, rest, opts, false)
+	if err != nil {
+		return "", xerrors.Errorf("changelog with slug \"%v\": %w", metadata.Slug, err)
+	}
+
+	changelog := readme.Changelog{
+		Slug:   metadata.Slug,
+		Title:  matter.Title,
+		Type:   matter.Type,
+		Body:   body,
+		Hidden: matter.Hidden,
+	}
+	date, _ := matter.date() // checked by validate
+	if !date.IsZero() {
+		changelog.CreatedAt = date.UTC().Format(time.RFC3339)
+	}
+
+	existing, err := c.GetChangelog(ctx, changelog.Slug)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+
+	var action report.Action
+	if existing.Id == "" {
+		action = report.Created
+		log.Info("Creating changelog", "slug", changelog.Slug)
+		if _, err := c.CreateChangelog(ctx, changelog); err != nil {
+			return "", xerrors.Errorf(": %w", err)
+		}
+	} else if changelogChanged(existing, changelog) {
+		action = report.Updated
+		if changelog.CreatedAt == "" {
+			existing.CreatedAt = "" // the publish date is kept when the front matter does not set one
+		}
+		log.Info("Updating changelog", append([]any{"slug", changelog.Slug}, changeAttrs(opts, canonicalChangelog(existing), canonicalChangelog(changelog), "body")...)...)
+		if err := c.UpdateChangelog(ctx, changelog); err != nil {
+			return "", xerrors.Errorf(": %w", err)
+		}
+	} else {
+		action = report.Unchanged
+		log.Info("No change to changelog", "slug", changelog.Slug)
+	}
+	return action, nil
+}
+
+// the publish date is only compared when the front matter sets one
+func changelogChanged(existing, local readme.Changelog) bool {
+	if local.CreatedAt != "" {
+		existingDate, err := time.Parse(time.RFC3339, existing.CreatedAt)
+		localDate, _ := time.Parse(time.RFC3339, local.CreatedAt)
+		if err != nil || !existingDate.Equal(localDate) {
+			return true
+		}
+	}
+	existing.Id, existing.CreatedAt, local.CreatedAt = "", "", ""
+	return canonicalChangelog(existing) != canonicalChangelog(local)
+}
+
+func canonicalChangelog(changelog readme.Changelog) readme.Changelog {
+	changelog.Body = normalizeMarkdown(changelog.Body)
+	return changelog
+}
@@ -0,0 +1,96 @@
+package docs
+
+import (
+	"context"
+	"os"
+
+	"github.com/rolflewis/readme-sync/logging"
+	"github.com/rolflewis/readme-sync/readme"
+	"github.com/rolflewis/readme-sync/report"
+	"golang.org/x/xerrors"
+)
+
+type customPageFrontMatter struct {
+	Title    string `yaml:"title"`
+	Hidden   bool   `yaml:"hidden"`
+	HtmlMode bool   `yaml:"htmlmode"` // the body is html rather than markdown
+}
+
+func ProcessCustomPage(ctx context.Context, c *readme.Client, metadata EntryMetadata, opts DocOptions) (report.Action, error) {
+	log := logging.FromContext(ctx)
+
+	f, err := os.Open(metadata.Filepath)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+	defer f.Close()
+
+	var matter customPageFrontMatter
+	rest, warnings, err := parseFrontMatter(f, &matter, opts.UnknownKeys)
+	if err != nil {
+		return "", xerrors.Errorf("custom page with slug \"%v\": %w", metadata.Slug, err)
+	}
+	for _, warning := range warnings {
+		log.Warn(warning, "custom-page", metadata.Slug)
+	}
+
+	body, err := renderBody(metadata.Roots, rest, opts, matter.HtmlMode)
+	if err != nil {
+		return "", xerrors.Errorf("custom page with slug \"%v\": %w", metadata.Slug, err)
+	}
+
+	page := readme.CustomPage{
+		Slug:     metadata.Slug,
+		Title:    matter.Title,
+		HtmlMode: matter.HtmlMode,
+		Hidden:   matter.Hidden,
+	}
+	if page.HtmlMode {
+		page.Html = body
+	} else {
+		page.Body = body
+	}
+
+	existing, err := c.GetCustomPage(ctx, page.Slug)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+
+	// readme keeps the content of the mode not in use, so only the active one is compared
+	compare := existing
+	compare.Id = ""
+	if compare.HtmlMode {
+		compare.Body = ""
+	} else {
+		compare.Html = ""
+	}
+
+	var action report.Action
+	if existing.Id == "" {
+		action = report.Created
+		log.Info("Creating custom page", "slug", page.Slug)
+		if _, err := c.CreateCustomPage(ctx, page); err != nil {
+			return "", xerrors.Errorf(": %w", err)
+		}
+	} else if !sameCustomPage(compare, page) {
+		action = report.Updated
+		log.Info("Updating custom page", append([]any{"slug", page.Slug}, changeAttrs(opts, canonicalCustomPage(compare), canonicalCustomPage(page), "body", "html")...)...)
+		if err := c.UpdateCustomPage(ctx, page); err != nil {
+			return "", xerrors.Errorf(": %w", err)
+		}
+	} else {
+		action = report.Unchanged
+		log.Info("No change to custom page", "slug", page.Slug)
+	}
+	return action, nil
+}
+
+func canonicalCustomPage(page readme.CustomPage) readme.CustomPage {
+	page.Body = normalizeMarkdown(page.Body)
+	page.Html = normalizeText(page.Html)
+	return page
+}
+
+func sameCustomPage(existing, local readme.CustomPage) bool {
+	return canonicalCustomPage(existing) == canonicalCustomPage(local)
+}
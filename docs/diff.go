@@ -0,0 +1,12 @@
+package docs
+
+import "github.com/rolflewis/readme-sync/diff"
+
+// changeAttrs returns log attributes describing how local differs from the existing remote item
+// when diffs are enabled. textFields are shown as unified diffs rather than whole values.
+func changeAttrs(opts DocOptions, existing, local any, textFields ...string) []any {
+	if !opts.Diff {
+		return nil
+	}
+	return []any{"diff", diff.Describe(existing, local, textFields, opts.Color)}
+}
@@ -2,14 +2,17 @@ package docs
 
 import (
 	"context"
-	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
-	"github.com/adrg/frontmatter"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/gosimple/slug"
+	"github.com/rolflewis/readme-sync/logging"
 	"github.com/rolflewis/readme-sync/readme"
+	"github.com/rolflewis/readme-sync/report"
 	"golang.org/x/xerrors"
 )
 
@@ -18,25 +21,84 @@ type DocMetadata struct {
 	Parent   string
 	Slug     string
 	Filepath string
+	Roots    []string // docs roots that include paths are resolved against
+	Includes []string // files pulled into the body by include directives
+	Blocks   []string // tags of reusable blocks embedded in the body
+}
+
+type SkippedFile struct {
+	Path   string
+	Reason string
 }
 
 type Catalog struct {
-	Categories map[string]struct{}
+	Categories map[string]CategoryFile
 	Docs       map[string]DocMetadata
+	Skipped    []SkippedFile
+}
+
+// DocsIncluding returns the slugs of all docs whose body depends on the file at path
+func (c Catalog) DocsIncluding(path string) []string {
+	var slugs []string
+	for slug, doc := range c.Docs {
+		for _, inc := range doc.Includes {
+			if inc == path {
+				slugs = append(slugs, slug)
+				break
+			}
+		}
+	}
+	return slugs
 }
 
-func WalkCatalog(ctx context.Context, docsPath string) (Catalog, error) {
+func WalkCatalog(ctx context.Context, docsPath string, opts WalkOptions) (Catalog, error) {
 	catalog := Catalog{
-		Categories: make(map[string]struct{}),
+		Categories: make(map[string]CategoryFile),
 		Docs:       make(map[string]DocMetadata),
 	}
 
-	cats, err := os.ReadDir(docsPath)
+	roots := []string{docsPath}
+	if opts.Overlay != "" {
+		roots = append(roots, opts.Overlay)
+	}
+
+	filter, err := newFileFilter(docsPath, opts)
+	if err != nil {
+		return Catalog{}, xerrors.Errorf(": %w", err)
+	}
+
+	// records the entry as skipped if the filter rejects it
+	skip := func(rel string, isDir bool) (bool, error) {
+		reason, err := filter.skipReason(rel, isDir)
+		if err != nil {
+			return false, xerrors.Errorf(": %w", err)
+		}
+		if reason != "" {
+			catalog.Skipped = append(catalog.Skipped, SkippedFile{Path: rel, Reason: reason})
+		}
+		return reason != "", nil
+	}
+
+	cats, err := readLayeredDir(roots, "")
 	if err != nil {
 		return Catalog{}, xerrors.Errorf(": %w", err)
 	}
 
 	for _, cat := range cats {
+		if cat.IsDir() && isPartialName(cat.Name()) {
+			continue // partials folder, only used through includes
+		}
+		if cat.Name() == ignoreFile {
+			continue
+		}
+		if cat.IsDir() && isReserved(opts.Reserved, cat.Name()) {
+			continue // changelog, custom pages or blocks folder configured inside the docs root
+		}
+		if skipped, err := skip(cat.Name(), cat.IsDir()); err != nil {
+			return Catalog{}, xerrors.Errorf(": %w", err)
+		} else if skipped {
+			continue
+		}
 		if !cat.IsDir() {
 			return Catalog{}, xerrors.New("found non-dir in categories layer")
 		}
@@ -47,15 +109,26 @@ func WalkCatalog(ctx context.Context, docsPath string) (Catalog, error) {
 		if _, dup := catalog.Categories[categorySlug]; dup {
 			return Catalog{}, xerrors.New("duplicate category slug detected")
 		}
-		catalog.Categories[categorySlug] = struct{}{}
+		catFile, err := readCategoryFile(resolveLayered(roots, path.Join(cat.Name(), categoryFile)))
+		if err != nil {
+			return Catalog{}, xerrors.Errorf(": %w", err)
+		}
+		catalog.Categories[categorySlug] = catFile
 
-		catPath := fmt.Sprintf("%v%v%v", docsPath, string(os.PathSeparator), cat.Name())
-		catContents, err := os.ReadDir(catPath)
+		catContents, err := readLayeredDir(roots, cat.Name())
 		if err != nil {
 			return Catalog{}, xerrors.Errorf(": %w", err)
 		}
 
 		for _, cc := range catContents {
+			if isPartialName(cc.Name()) {
+				continue
+			}
+			if skipped, err := skip(path.Join(cat.Name(), cc.Name()), cc.IsDir()); err != nil {
+				return Catalog{}, xerrors.Errorf(": %w", err)
+			} else if skipped {
+				continue
+			}
 			if !cc.IsDir() { // doc with no parent
 				slug := slug.Make(strings.TrimSuffix(cc.Name(), filepath.Ext(cc.Name())))
 				if _, dup := catalog.Docs[slug]; dup {
@@ -64,11 +137,10 @@ func WalkCatalog(ctx context.Context, docsPath string) (Catalog, error) {
 				catalog.Docs[slug] = DocMetadata{
 					Category: categorySlug,
 					Slug:     slug,
-					Filepath: fmt.Sprintf("%v%v%v", catPath, string(os.PathSeparator), cc.Name()),
+					Filepath: cc.Path,
 				}
 			} else { // doc with a parent
-				folderPath := fmt.Sprintf("%v%v%v", catPath, string(os.PathSeparator), cc.Name())
-				foldContents, err := os.ReadDir(folderPath)
+				foldContents, err := readLayeredDir(roots, path.Join(cat.Name(), cc.Name()))
 				if err != nil {
 					return Catalog{}, xerrors.Errorf(": %w", err)
 				}
@@ -76,6 +148,14 @@ func WalkCatalog(ctx context.Context, docsPath string) (Catalog, error) {
 				var foundFolderPage bool // need a doc with same slug as folder inside folder
 				folderSlug := slug.Make(cc.Name())
 				for _, fc := range foldContents {
+					if isPartialName(fc.Name()) {
+						continue
+					}
+					if skipped, err := skip(path.Join(cat.Name(), cc.Name(), fc.Name()), fc.IsDir()); err != nil {
+						return Catalog{}, xerrors.Errorf(": %w", err)
+					} else if skipped {
+						continue
+					}
 					if fc.IsDir() {
 						return Catalog{}, xerrors.New("nested too deep")
 					}
@@ -88,7 +168,7 @@ func WalkCatalog(ctx context.Context, docsPath string) (Catalog, error) {
 					meta := DocMetadata{
 						Category: categorySlug,
 						Slug:     slug,
-						Filepath: fmt.Sprintf("%v%v%v", folderPath, string(os.PathSeparator), fc.Name()),
+						Filepath: fc.Path,
 					}
 
 					if folderSlug == slug {
@@ -107,14 +187,49 @@ func WalkCatalog(ctx context.Context, docsPath string) (Catalog, error) {
 		}
 	}
 
+	// resolve includes up front so cycles fail the walk and dependents and embedded blocks are known
+	for slug, doc := range catalog.Docs {
+		doc.Roots = roots
+		contents, err := os.ReadFile(doc.Filepath)
+		if err != nil {
+			return Catalog{}, xerrors.Errorf(": %w", err)
+		}
+		expanded, includes, err := expandIncludes(roots, contents, false)
+		if err != nil {
+			return Catalog{}, xerrors.Errorf("doc with slug \"%v\": %w", slug, err)
+		}
+		doc.Includes = includes
+		if doc.Blocks, err = blockReferences(expanded); err != nil {
+			return Catalog{}, xerrors.Errorf("doc with slug \"%v\": %w", slug, err)
+		}
+		catalog.Docs[slug] = doc
+	}
+
 	return catalog, nil
 }
 
 type docFrontMatter struct {
-	Title   string `yaml:"title"`
-	Excerpt string `yaml:"excerpt"`
-	Order   int    `yaml:"order"`
-	Hidden  bool   `yaml:"hidden"`
+	Title        string `yaml:"title"`
+	Type         string `yaml:"type"`
+	Excerpt      string `yaml:"excerpt"`
+	Order        int    `yaml:"order"`
+	Hidden       bool   `yaml:"hidden"`
+	LinkUrl      string `yaml:"link_url"`
+	LinkExternal bool   `yaml:"link_external"`
+	Metadata     struct {
+		Title       string `yaml:"title"`
+		Description string `yaml:"description"`
+		Image       string `yaml:"image"`
+	} `yaml:"metadata"`
+	Next struct {
+		Description string `yaml:"description"`
+		Pages       []struct {
+			Type string `yaml:"type"`
+			Slug string `yaml:"slug"`
+			Name string `yaml:"name"`
+		} `yaml:"pages"`
+	} `yaml:"next"`
+	ErrorCode string `yaml:"error_code"`
 }
 
 func (fm *docFrontMatter) validate() error {
@@ -124,53 +239,166 @@ func (fm *docFrontMatter) validate() error {
 	if fm.Order > 999 || fm.Order < 0 {
 		return xerrors.New("order must be between 0 and 999 inclusive")
 	}
+
+	switch fm.Type {
+	case "", readme.DocTypeBasic, readme.DocTypeError:
+		if fm.LinkUrl != "" || fm.LinkExternal {
+			return xerrors.New("link_url and link_external are only allowed on link docs")
+		}
+	case readme.DocTypeLink:
+		if fm.LinkUrl == "" {
+			return xerrors.New("link_url is required for link docs")
+		}
+	default:
+		return xerrors.New("type must be one of basic, link or error")
+	}
+	if fm.ErrorCode != "" && fm.Type != readme.DocTypeError {
+		return xerrors.New("error_code is only allowed on error docs")
+	}
+
+	for _, page := range fm.Next.Pages {
+		if page.Slug == "" || page.Name == "" {
+			return xerrors.New("next step pages require a slug and name")
+		}
+		if page.Type != "" && page.Type != "doc" && page.Type != "ref" {
+			return xerrors.New("next step page type must be doc or ref")
+		}
+	}
 	return nil
 }
 
-func ProcessDoc(ctx context.Context, c *readme.Client, metadata DocMetadata) error {
+// renderBody expands includes, substitutes variables and applies markdown transforms.
+// Html bodies are not markdown, so variables are substituted everywhere and transforms do not apply.
+func renderBody(roots []string, rest []byte, opts DocOptions, html bool) (string, error) {
+	rest, _, err := expandIncludes(roots, rest, html)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+
+	if html {
+		body, err := substituteVariables(string(rest), opts.Variables)
+		if err != nil {
+			return "", xerrors.Errorf(": %w", err)
+		}
+		return strings.TrimSpace(body), nil
+	}
+
+	body, err := substituteMarkdownVariables(string(rest), opts.Variables)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+
+	transformed, err := applyTransforms([]byte(body), opts.Transforms)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+	return strings.TrimSpace(string(transformed)), nil // readme cleans whitespace
+}
+
+func ProcessDoc(ctx context.Context, c *readme.Client, metadata DocMetadata, opts DocOptions) (report.Action, error) {
+	log := logging.FromContext(ctx)
+
 	f, err := os.Open(metadata.Filepath)
 	if err != nil {
-		return xerrors.Errorf(": %w", err)
+		return "", xerrors.Errorf(": %w", err)
 	}
 
+	defer f.Close()
+
 	var matter docFrontMatter
-	rest, err := frontmatter.MustParse(f, &matter)
+	rest, warnings, err := parseFrontMatter(f, &matter, opts.UnknownKeys)
 	if err != nil {
-		return xerrors.Errorf(": %w", err)
+		return "", xerrors.Errorf("doc with slug \"%v\": %w", metadata.Slug, err)
+	}
+	for _, warning := range warnings {
+		log.Warn(warning, "doc", metadata.Slug)
+	}
+
+	for _, field := range []*string{&matter.Title, &matter.Excerpt, &matter.Metadata.Title, &matter.Metadata.Description} {
+		if *field, err = substituteVariables(*field, opts.Variables); err != nil {
+			return "", xerrors.Errorf("doc with slug \"%v\": %w", metadata.Slug, err)
+		}
 	}
 
 	if err := matter.validate(); err != nil {
-		return xerrors.Errorf(": %w", err)
+		return "", xerrors.Errorf(": %w", err)
+	}
+
+	body, err := renderBody(metadata.Roots, rest, opts, false)
+	if err != nil {
+		return "", xerrors.Errorf("doc with slug \"%v\": %w", metadata.Slug, err)
 	}
 
 	document := readme.Document{
-		Category: metadata.Category,
-		Parent:   metadata.Parent,
-		Slug:     metadata.Slug,
-		Title:    matter.Title,
-		Excerpt:  matter.Excerpt,
-		Order:    matter.Order,
-		Hidden:   matter.Hidden,
-		Body:     strings.TrimSpace(string(rest)), // readme cleans whitespace
+		Category:     metadata.Category,
+		Parent:       metadata.Parent,
+		Slug:         metadata.Slug,
+		Title:        matter.Title,
+		Type:         matter.Type,
+		Excerpt:      matter.Excerpt,
+		Order:        matter.Order,
+		Hidden:       matter.Hidden,
+		Body:         body,
+		LinkUrl:      matter.LinkUrl,
+		LinkExternal: matter.LinkExternal,
+		Metadata: readme.DocSeo{
+			Title:       matter.Metadata.Title,
+			Description: matter.Metadata.Description,
+			Image:       readme.ImageRef(matter.Metadata.Image),
+		},
+		Next: readme.NextSteps{
+			Description: matter.Next.Description,
+		},
+		Error: readme.ErrorDetails{
+			Code: matter.ErrorCode,
+		},
+	}
+	if document.Type == "" {
+		document.Type = readme.DocTypeBasic
+	}
+	for _, page := range matter.Next.Pages {
+		next := readme.NextPage{
+			Type: page.Type,
+			Slug: page.Slug,
+			Name: page.Name,
+		}
+		if next.Type == "" {
+			next.Type = "doc"
+		}
+		document.Next.Pages = append(document.Next.Pages, next)
 	}
 
 	existing, err := c.GetDoc(ctx, document.Slug)
 	if err != nil {
-		return xerrors.Errorf(": %w", err)
+		return "", xerrors.Errorf(": %w", err)
 	}
 
-	if existing == (readme.Document{}) {
-		fmt.Printf("Creating doc with slug \"%v\"\n", document.Slug)
+	var action report.Action
+	if existing.Id == "" {
+		action = report.Created
+		log.Info("Creating doc", "slug", document.Slug)
 		if err := c.CreateDoc(ctx, document); err != nil {
-			return xerrors.Errorf(": %w", err)
+			return "", xerrors.Errorf(": %w", err)
 		}
-	} else if document.Id = existing.Id; existing != document {
-		fmt.Printf("Updating doc with slug \"%v\"\n", document.Slug)
+	} else if document.Id = existing.Id; !sameDoc(existing, document) {
+		action = report.Updated
+		log.Info("Updating doc", append([]any{"slug", document.Slug}, changeAttrs(opts, canonicalDoc(existing), canonicalDoc(document), "body")...)...)
 		if err := c.PutDoc(ctx, document); err != nil {
-			return xerrors.Errorf(": %w", err)
+			return "", xerrors.Errorf(": %w", err)
 		}
 	} else {
-		fmt.Printf("No change to doc with slug \"%v\"\n", document.Slug)
+		action = report.Unchanged
+		log.Info("No change to doc", "slug", document.Slug)
 	}
-	return nil
+	return action, nil
+}
+
+// readme normalizes the markdown it stores, so bodies are compared and diffed in canonical form
+func canonicalDoc(doc readme.Document) readme.Document {
+	doc.Body = normalizeMarkdown(doc.Body)
+	return doc
+}
+
+func sameDoc(existing, local readme.Document) bool {
+	return cmp.Equal(canonicalDoc(existing), canonicalDoc(local), cmpopts.EquateEmpty())
 }
@@ -0,0 +1,77 @@
+package docs
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/gosimple/slug"
+	"golang.org/x/xerrors"
+)
+
+// EntryMetadata locates a changelog entry or custom page
+type EntryMetadata struct {
+	Slug     string
+	Filepath string
+	Roots    []string
+	Blocks   []string // tags of reusable blocks embedded in the body
+}
+
+// walkEntries catalogs a flat folder of changelog entries or custom pages.
+// Readme derives their slugs from titles, so entries are keyed by slugified title rather than file name.
+func walkEntries(folder string, skip func(string, bool) (bool, error)) (map[string]EntryMetadata, error) {
+	roots := []string{folder}
+	files, err := readLayeredDir(roots, "")
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+
+	entries := make(map[string]EntryMetadata)
+	for _, file := range files {
+		if isPartialName(file.Name()) {
+			continue
+		}
+		if skipped, err := skip(file.Name(), file.IsDir()); err != nil {
+			return nil, xerrors.Errorf(": %w", err)
+		} else if skipped {
+			continue
+		}
+		if file.IsDir() {
+			return nil, xerrors.New(folder + ": cannot contain folders")
+		}
+
+		contents, err := os.ReadFile(file.Path)
+		if err != nil {
+			return nil, xerrors.Errorf(": %w", err)
+		}
+		var matter struct {
+			Title string `yaml:"title"`
+		}
+		// other keys are checked when processed
+		if _, _, err := parseFrontMatter(bytes.NewReader(contents), &matter, UnknownKeysWarn); err != nil {
+			return nil, xerrors.Errorf("%v: %w", file.Path, err)
+		}
+		if matter.Title == "" {
+			return nil, xerrors.New(file.Path + ": title is required")
+		}
+
+		slug := slug.Make(matter.Title)
+		if _, dup := entries[slug]; dup {
+			return nil, xerrors.New(folder + ": duplicate slug " + slug)
+		}
+		expanded, _, err := expandIncludes(roots, contents, false)
+		if err != nil {
+			return nil, xerrors.Errorf("%v: %w", file.Path, err)
+		}
+		blocks, err := blockReferences(expanded)
+		if err != nil {
+			return nil, xerrors.Errorf("%v: %w", file.Path, err)
+		}
+		entries[slug] = EntryMetadata{
+			Slug:     slug,
+			Filepath: file.Path,
+			Roots:    roots,
+			Blocks:   blocks,
+		}
+	}
+	return entries, nil
+}
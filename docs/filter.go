@@ -0,0 +1,230 @@
+package docs
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const ignoreFile = ".readmesyncignore"
+
+var defaultInclude = []string{"*.md", "*.mdx"}
+
+type WalkOptions struct {
+	Include  []string // globs a file must match to become a doc, defaults to *.md and *.mdx
+	Exclude  []string // globs that skip matching files and folders
+	Overlay  string   // optional folder layered over the docs root, replacing and adding files
+	Reserved []string // top-level folders that are not categories, such as a changelog folder kept in the docs root
+}
+
+func isReserved(reserved []string, name string) bool {
+	for _, r := range reserved {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// globs without a slash match the base name, globs with a slash match the path relative to the docs root
+func matchGlob(pattern, rel string) (bool, error) {
+	target := rel
+	if !strings.Contains(pattern, "/") {
+		target = path.Base(rel)
+	}
+	matched, err := path.Match(strings.TrimPrefix(pattern, "/"), target)
+	if err != nil {
+		return false, xerrors.Errorf(": %w", err)
+	}
+	return matched, nil
+}
+
+func matchAny(patterns []string, rel string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := matchGlob(pattern, rel)
+		if err != nil {
+			return false, xerrors.Errorf(": %w", err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type ignoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// fileFilter decides which entries of the docs tree are synced
+type fileFilter struct {
+	include []string
+	exclude []string
+	ignore  []ignoreRule
+}
+
+func newFileFilter(docsPath string, opts WalkOptions) (fileFilter, error) {
+	filter := fileFilter{
+		include: opts.Include,
+		exclude: opts.Exclude,
+	}
+	if len(filter.include) == 0 {
+		filter.include = defaultInclude
+	}
+
+	// validate globs up front instead of failing part way through the walk
+	for _, pattern := range append(append([]string{}, filter.include...), filter.exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fileFilter{}, xerrors.Errorf("bad glob %q: %w", pattern, err)
+		}
+	}
+
+	rules, err := readIgnoreFile(filepath.Join(docsPath, ignoreFile))
+	if err != nil {
+		return fileFilter{}, xerrors.Errorf(": %w", err)
+	}
+	filter.ignore = rules
+
+	return filter, nil
+}
+
+// skipReason returns a non-empty reason when the entry at rel (slash separated, relative to the docs root) is skipped
+func (f fileFilter) skipReason(rel string, isDir bool) (string, error) {
+	if f.ignored(rel, isDir) {
+		return "matched " + ignoreFile, nil
+	}
+	excluded, err := matchAny(f.exclude, rel)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+	if excluded {
+		return "matched exclude glob", nil
+	}
+	if !isDir {
+		included, err := matchAny(f.include, rel)
+		if err != nil {
+			return "", xerrors.Errorf(": %w", err)
+		}
+		if !included {
+			return "did not match include globs", nil
+		}
+	}
+	return "", nil
+}
+
+// gitignore semantics: the last matching rule wins and negated rules re-include
+func (f fileFilter) ignored(rel string, isDir bool) bool {
+	var ignored bool
+	for _, rule := range f.ignore {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.pattern.MatchString(rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func readIgnoreFile(path string) ([]ignoreRule, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+	defer f.Close()
+
+	rules, err := parseIgnoreRules(f)
+	if err != nil {
+		return nil, xerrors.Errorf("%v: %w", path, err)
+	}
+	return rules, nil
+}
+
+// parseIgnoreRules reads rules in .gitignore syntax, one per line
+func parseIgnoreRules(r io.Reader) ([]ignoreRule, error) {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) { // escaped leading ! or #
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+
+		// a slash anywhere but the end anchors the pattern to the root
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+
+		expr := globToRegexp(line)
+		if !anchored {
+			expr = "(?:.*/)?" + expr
+		}
+		pattern, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return nil, xerrors.Errorf(": %w", err)
+		}
+		rule.pattern = pattern
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+	return rules, nil
+}
+
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch ch := glob[i]; ch {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**/") {
+				sb.WriteString("(?:.*/)?")
+				i += 2
+			} else if strings.HasPrefix(glob[i:], "**") {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	return sb.String()
+}
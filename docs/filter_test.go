@@ -0,0 +1,50 @@
+package docs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		rel     string
+		isDir   bool
+		ignored bool
+	}{
+		{"base name matches at any depth", "*.draft.md", "guides/deep/intro.draft.md", false, true},
+		{"base name does not match other files", "*.draft.md", "guides/intro.md", false, false},
+		{"leading slash anchors to the root", "/internal.md", "internal.md", false, true},
+		{"anchored rule does not match below the root", "/internal.md", "guides/internal.md", false, false},
+		{"inner slash anchors to the root", "guides/*.md", "guides/intro.md", false, true},
+		{"anchored rule does not match deeper folders", "guides/*.md", "api/guides/intro.md", false, false},
+		{"single star does not cross folders", "guides/*.md", "guides/sub/intro.md", false, false},
+		{"double star matches any folders", "**/secret.md", "a/b/secret.md", false, true},
+		{"double star matches no folder", "**/secret.md", "secret.md", false, true},
+		{"trailing double star matches everything inside", "drafts/**", "drafts/a/b.md", false, true},
+		{"dir-only rule matches folders", "drafts/", "drafts", true, true},
+		{"dir-only rule does not match files", "drafts/", "drafts", false, false},
+		{"dir-only rule matches nested folders", "drafts/", "guides/drafts", true, true},
+		{"negation re-includes", "*.md\n!keep.md", "keep.md", false, false},
+		{"negation leaves other matches ignored", "*.md\n!keep.md", "other.md", false, true},
+		{"last matching rule wins", "!keep.md\n*.md", "keep.md", false, true},
+		{"negated dir-only rule does not re-include files", "*.md\n!keep.md/", "keep.md", false, true},
+		{"escaped bang is literal", `\!bang.md`, "!bang.md", false, true},
+		{"comments and blank lines are skipped", "# intro.md\n\n", "intro.md", false, false},
+		{"question mark matches one character", "v?.md", "v1.md", false, true},
+		{"negated class", "v[!0-9].md", "v1.md", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := parseIgnoreRules(strings.NewReader(tt.rules))
+			if err != nil {
+				t.Fatalf("parseIgnoreRules: %v", err)
+			}
+			filter := fileFilter{ignore: rules}
+			if got := filter.ignored(tt.rel, tt.isDir); got != tt.ignored {
+				t.Errorf("ignored(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.ignored)
+			}
+		})
+	}
+}
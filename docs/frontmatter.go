@@ -0,0 +1,90 @@
+package docs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/adrg/frontmatter"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+// How unknown front matter keys are handled
+const (
+	UnknownKeysError = "error"
+	UnknownKeysWarn  = "warn"
+)
+
+type DocOptions struct {
+	Variables   map[string]string
+	UnknownKeys string   // UnknownKeysError (default) or UnknownKeysWarn
+	Transforms  []string // markdown transforms applied to the body, in order
+	Diff        bool     // log what differs when updating an item
+	Color       bool     // colorize diffs
+}
+
+// parseFrontMatter decodes the front matter of r into v and returns the remaining body.
+// YAML front matter is expected, but the TOML and JSON formats are accepted too and decoded
+// with the same yaml tags and checks. Values of the wrong type always fail. Keys that do not
+// exist on v fail or produce a warning depending on unknownKeys. Warnings are returned
+// alongside the body.
+func parseFrontMatter(r io.Reader, v any, unknownKeys string) ([]byte, []string, error) {
+	var unknown []string
+	strict := func(data []byte, v any) error {
+		err := decodeYaml(data, v, true)
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return err
+		}
+
+		// split out unknown key errors so wrong types are never downgraded to warnings
+		for _, msg := range typeErr.Errors {
+			if !strings.Contains(msg, "not found in type") {
+				return err
+			}
+			unknown = append(unknown, msg)
+		}
+		if unknownKeys != UnknownKeysWarn {
+			return err
+		}
+		return decodeYaml(data, v, false)
+	}
+	// toml is converted to yaml first, json already is yaml
+	strictToml := func(data []byte, v any) error {
+		var values map[string]any
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return err
+		}
+		converted, err := yaml.Marshal(values)
+		if err != nil {
+			return err
+		}
+		return strict(converted, v)
+	}
+
+	rest, err := frontmatter.MustParse(r, v,
+		frontmatter.NewFormat("---", "---", strict),
+		frontmatter.NewFormat("---yaml", "---", strict),
+		frontmatter.NewFormat("+++", "+++", strictToml),
+		frontmatter.NewFormat("---toml", "---", strictToml),
+		frontmatter.NewFormat(";;;", ";;;", strict),
+		frontmatter.NewFormat("---json", "---", strict),
+		&frontmatter.Format{Start: "{", End: "}", Unmarshal: strict, UnmarshalDelims: true, RequiresNewLine: true},
+	)
+	if err != nil {
+		return nil, nil, xerrors.Errorf(": %w", err)
+	}
+	return rest, unknown, nil
+}
+
+func decodeYaml(data []byte, v any, knownFields bool) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(knownFields)
+	if err := dec.Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package docs
+
+import (
+	"strings"
+	"testing"
+)
+
+type testFrontMatter struct {
+	Title  string `yaml:"title"`
+	Hidden bool   `yaml:"hidden"`
+	Order  int    `yaml:"order"`
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		unknownKeys string
+		want        testFrontMatter
+		body        string
+		warnings    int
+		wantErr     string
+	}{
+		{
+			name:  "yaml",
+			input: "---\ntitle: Intro\nhidden: true\norder: 2\n---\nBody\n",
+			want:  testFrontMatter{Title: "Intro", Hidden: true, Order: 2},
+			body:  "Body\n",
+		},
+		{
+			name:  "yaml with explicit format",
+			input: "---yaml\ntitle: Intro\n---\nBody\n",
+			want:  testFrontMatter{Title: "Intro"},
+			body:  "Body\n",
+		},
+		{
+			name:  "toml",
+			input: "+++\ntitle = \"Intro\"\nhidden = true\norder = 2\n+++\nBody\n",
+			want:  testFrontMatter{Title: "Intro", Hidden: true, Order: 2},
+			body:  "Body\n",
+		},
+		{
+			name:  "toml with explicit format",
+			input: "---toml\ntitle = \"Intro\"\n---\nBody\n",
+			want:  testFrontMatter{Title: "Intro"},
+			body:  "Body\n",
+		},
+		{
+			name:  "json between semicolons",
+			input: ";;;\n{\"title\": \"Intro\", \"hidden\": true, \"order\": 2}\n;;;\nBody\n",
+			want:  testFrontMatter{Title: "Intro", Hidden: true, Order: 2},
+			body:  "Body\n",
+		},
+		{
+			name:  "json with explicit format",
+			input: "---json\n{\"title\": \"Intro\"}\n---\nBody\n",
+			want:  testFrontMatter{Title: "Intro"},
+			body:  "Body\n",
+		},
+		{
+			name:  "bare json object",
+			input: "{\n  \"title\": \"Intro\",\n  \"order\": 2\n}\n\nBody\n",
+			want:  testFrontMatter{Title: "Intro", Order: 2},
+			body:  "Body\n",
+		},
+		{
+			name:    "bare json object needs a blank line after it",
+			input:   "{\n  \"title\": \"Intro\"\n}\nBody\n",
+			wantErr: "not found",
+		},
+		{
+			name:    "missing front matter",
+			input:   "Body\n",
+			wantErr: "not found",
+		},
+		{
+			name:    "unknown key fails by default",
+			input:   "---\ntitle: Intro\nslug: intro\n---\nBody\n",
+			wantErr: "field slug not found",
+		},
+		{
+			name:        "unknown key warns",
+			input:       "---\ntitle: Intro\nslug: intro\n---\nBody\n",
+			unknownKeys: UnknownKeysWarn,
+			want:        testFrontMatter{Title: "Intro"},
+			body:        "Body\n",
+			warnings:    1,
+		},
+		{
+			name:        "unknown toml key warns",
+			input:       "+++\ntitle = \"Intro\"\nslug = \"intro\"\n+++\nBody\n",
+			unknownKeys: UnknownKeysWarn,
+			want:        testFrontMatter{Title: "Intro"},
+			body:        "Body\n",
+			warnings:    1,
+		},
+		{
+			name:    "unknown json key fails",
+			input:   ";;;\n{\"title\": \"Intro\", \"slug\": \"intro\"}\n;;;\nBody\n",
+			wantErr: "field slug not found",
+		},
+		{
+			name:        "wrong type fails even when warning",
+			input:       "---\ntitle: Intro\nslug: intro\norder: first\n---\nBody\n",
+			unknownKeys: UnknownKeysWarn,
+			wantErr:     "cannot unmarshal",
+		},
+		{
+			name:    "wrong toml type fails",
+			input:   "+++\norder = \"first\"\n+++\nBody\n",
+			wantErr: "cannot unmarshal",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testFrontMatter
+			body, warnings, err := parseFrontMatter(strings.NewReader(tt.input), &got, tt.unknownKeys)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFrontMatter: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("front matter = %+v, want %+v", got, tt.want)
+			}
+			if string(body) != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("warnings = %q, want %v", warnings, tt.warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// matches both {{< include "path" >}} and <!-- include "path" --> directives, and their escaped forms with a leading backslash
+var includePattern = regexp.MustCompile(`(\\?)(?:\{\{<\s*include\s+"([^"]+)"\s*>\}\}|<!--\s*include\s+"([^"]+)"\s*-->)`)
+
+// partials live in folders prefixed with an underscore and are never synced as docs
+func isPartialName(name string) bool {
+	return strings.HasPrefix(name, "_")
+}
+
+// expandIncludes replaces all include directives in body with the contents of the referenced files.
+// Include paths are resolved relative to the docs roots, preferring overlays. Nested includes are
+// expanded recursively. In markdown, directives in code spans and blocks are left alone, and an
+// escaped directive is kept without its backslash elsewhere. Returns the expanded body and the set
+// of files it depends on.
+func expandIncludes(roots []string, body []byte, html bool) ([]byte, []string, error) {
+	var deps []string
+	seen := make(map[string]struct{})
+	expanded, err := expandIncludesInner(roots, body, html, nil, func(path string) {
+		if _, found := seen[path]; !found {
+			seen[path] = struct{}{}
+			deps = append(deps, path)
+		}
+	})
+	if err != nil {
+		return nil, nil, xerrors.Errorf(": %w", err)
+	}
+	return expanded, deps, nil
+}
+
+// includePath resolves an include target against the docs roots. Targets are always relative to a root,
+// and neither the target nor a symlink it passes through may lead out of the roots, so that files
+// elsewhere on the machine are never uploaded.
+func includePath(roots []string, target string) (string, error) {
+	cleaned := path.Clean(filepath.ToSlash(target))
+	if path.IsAbs(cleaned) || filepath.IsAbs(target) || filepath.VolumeName(target) != "" ||
+		cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", xerrors.New(fmt.Sprintf("include \"%v\" is outside the docs root", target))
+	}
+
+	resolved := resolveLayered(roots, cleaned)
+	real, err := filepath.EvalSymlinks(resolved)
+	if err != nil {
+		return "", xerrors.Errorf("include \"%v\": %w", target, err)
+	}
+	for _, root := range roots {
+		realRoot, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(realRoot, real); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", xerrors.New(fmt.Sprintf("include \"%v\" is outside the docs root", target))
+}
+
+func expandIncludesInner(roots []string, body []byte, html bool, stack []string, record func(string)) ([]byte, error) {
+	var skip [][2]int
+	if !html {
+		skip = codeRanges(body)
+	}
+
+	var b bytes.Buffer
+	last := 0
+	for _, match := range includePattern.FindAllSubmatchIndex(body, -1) {
+		start, stop := match[0], match[1]
+		if inRanges(skip, start) {
+			continue
+		}
+		b.Write(body[last:start])
+		last = stop
+
+		if match[3] > match[2] { // escaped
+			b.Write(body[match[3]:stop])
+			continue
+		}
+		var target string
+		if match[4] >= 0 {
+			target = string(body[match[4]:match[5]])
+		} else {
+			target = string(body[match[6]:match[7]])
+		}
+
+		path, err := includePath(roots, target)
+		if err != nil {
+			return nil, xerrors.Errorf(": %w", err)
+		}
+		for _, parent := range stack {
+			if parent == path {
+				chain := append(append([]string{}, stack...), path)
+				return nil, xerrors.New(fmt.Sprintf("include cycle detected: %v", strings.Join(chain, " -> ")))
+			}
+		}
+		record(path)
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, xerrors.Errorf(": %w", err)
+		}
+
+		nested, err := expandIncludesInner(roots, contents, html, append(stack, path), record)
+		if err != nil {
+			return nil, xerrors.Errorf(": %w", err)
+		}
+		b.WriteString(strings.TrimRight(string(nested), "\r\n"))
+	}
+	b.Write(body[last:])
+	return b.Bytes(), nil
+}
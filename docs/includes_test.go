@@ -0,0 +1,182 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExpandIncludes(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"_partials/note.md":   "A note.\n",
+		"_partials/outer.md":  "Outer {{< include \"_partials/note.md\" >}}\n",
+		"_partials/a.md":      "{{< include \"_partials/b.md\" >}}",
+		"_partials/b.md":      "{{< include \"_partials/a.md\" >}}",
+		"_partials/self.md":   "<!-- include \"_partials/self.md\" -->",
+		"_partials/sample.md": "```\n{{< include \"_partials/note.md\" >}}\n```\n",
+	})
+
+	tests := []struct {
+		name    string
+		body    string
+		html    bool
+		want    string
+		deps    []string
+		wantErr string
+	}{
+		{
+			name: "shortcode",
+			body: "Before {{< include \"_partials/note.md\" >}} after",
+			want: "Before A note. after",
+			deps: []string{"_partials/note.md"},
+		},
+		{
+			name: "html comment",
+			body: "<!-- include \"_partials/note.md\" -->",
+			want: "A note.",
+			deps: []string{"_partials/note.md"},
+		},
+		{
+			name: "nested",
+			body: "{{< include \"_partials/outer.md\" >}}",
+			want: "Outer A note.",
+			deps: []string{"_partials/outer.md", "_partials/note.md"},
+		},
+		{
+			name: "fenced code is left alone",
+			body: "```md\n{{< include \"_partials/note.md\" >}}\n```\n",
+			want: "```md\n{{< include \"_partials/note.md\" >}}\n```\n",
+		},
+		{
+			name: "code span is left alone",
+			body: "Write `<!-- include \"_partials/note.md\" -->` to include.",
+			want: "Write `<!-- include \"_partials/note.md\" -->` to include.",
+		},
+		{
+			name: "code in included files is left alone",
+			body: "{{< include \"_partials/sample.md\" >}}",
+			want: "```\n{{< include \"_partials/note.md\" >}}\n```",
+			deps: []string{"_partials/sample.md"},
+		},
+		{
+			name: "escaped directive",
+			body: "\\{{< include \"_partials/note.md\" >}}",
+			want: "{{< include \"_partials/note.md\" >}}",
+		},
+		{
+			name: "html bodies have no code to skip",
+			body: "<pre>{{< include \"_partials/note.md\" >}}</pre>",
+			html: true,
+			want: "<pre>A note.</pre>",
+			deps: []string{"_partials/note.md"},
+		},
+		{
+			name:    "cycle",
+			body:    "{{< include \"_partials/a.md\" >}}",
+			wantErr: "include cycle detected",
+		},
+		{
+			name:    "self include",
+			body:    "{{< include \"_partials/self.md\" >}}",
+			wantErr: "include cycle detected",
+		},
+		{
+			name:    "missing file",
+			body:    "{{< include \"_partials/missing.md\" >}}",
+			wantErr: "_partials/missing.md",
+		},
+		{
+			name:    "outside the root",
+			body:    "{{< include \"../secret.md\" >}}",
+			wantErr: "outside the docs root",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, deps, err := expandIncludes([]string{root}, []byte(tt.body), tt.html)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandIncludes: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+			var want []string
+			for _, dep := range tt.deps {
+				want = append(want, filepath.Join(root, filepath.FromSlash(dep)))
+			}
+			if strings.Join(deps, "\n") != strings.Join(want, "\n") {
+				t.Errorf("deps = %q, want %q", deps, want)
+			}
+		})
+	}
+}
+
+func TestIncludePath(t *testing.T) {
+	outside := t.TempDir()
+	writeFiles(t, outside, map[string]string{"secret.md": "secret"})
+
+	root := t.TempDir()
+	overlay := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"_partials/base.md":   "base",
+		"_partials/shared.md": "root",
+	})
+	writeFiles(t, overlay, map[string]string{"_partials/shared.md": "overlay"})
+	if err := os.Symlink(filepath.Join(outside, "secret.md"), filepath.Join(root, "_partials", "link.md")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	roots := []string{root, overlay}
+
+	tests := []struct {
+		name   string
+		target string
+		want   string // empty when an error is expected
+	}{
+		{"in the root", "_partials/base.md", filepath.Join(root, "_partials", "base.md")},
+		{"overlay wins", "_partials/shared.md", filepath.Join(overlay, "_partials", "shared.md")},
+		{"dot segments inside the root", "_partials/../_partials/base.md", filepath.Join(root, "_partials", "base.md")},
+		{"parent directory", "../secret.md", ""},
+		{"parent directory after cleaning", "_partials/../../secret.md", ""},
+		{"absolute path", filepath.Join(outside, "secret.md"), ""},
+		{"symlink out of the root", "_partials/link.md", ""},
+		{"missing file", "_partials/missing.md", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := includePath(roots, tt.target)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("includePath: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("includePath = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/xerrors"
+)
+
+// layeredEntry is a directory entry merged across docs roots, along with where it was found
+type layeredEntry struct {
+	os.DirEntry
+	Path string
+}
+
+// readLayeredDir lists rel in every root, later roots overlaying earlier ones.
+// A file in a later root replaces the file with the same name in an earlier root,
+// while folders present in several roots are merged when they are read in turn.
+func readLayeredDir(roots []string, rel string) ([]layeredEntry, error) {
+	merged := make(map[string]layeredEntry)
+	var found bool
+	for _, root := range roots {
+		dir := filepath.Join(root, filepath.FromSlash(rel))
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, xerrors.Errorf(": %w", err)
+		}
+		found = true
+
+		for _, entry := range entries {
+			if prev, ok := merged[entry.Name()]; ok && prev.IsDir() != entry.IsDir() {
+				return nil, xerrors.New("overlay replaces a folder with a file or vice versa: " + filepath.Join(dir, entry.Name()))
+			}
+			merged[entry.Name()] = layeredEntry{
+				DirEntry: entry,
+				Path:     filepath.Join(dir, entry.Name()),
+			}
+		}
+	}
+	if !found {
+		return nil, xerrors.New("no such directory in any docs root: " + rel)
+	}
+
+	// keep the sorted order os.ReadDir gives for a single root
+	out := make([]layeredEntry, 0, len(merged))
+	for _, entry := range merged {
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out, nil
+}
+
+// resolveLayered returns the path of rel in the last root that contains it
+func resolveLayered(roots []string, rel string) string {
+	for i := len(roots) - 1; i > 0; i-- {
+		candidate := filepath.Join(roots[i], filepath.FromSlash(rel))
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return filepath.Join(roots[0], filepath.FromSlash(rel))
+}
@@ -0,0 +1,124 @@
+package docs
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// normalizeMarkdown canonicalizes a body for comparison only, so differences readme introduces
+// when it stores content (line endings, trailing whitespace, blank lines, list markers) do not
+// count as changes. Code blocks are left as they are, hard line breaks are kept, and the result
+// is never uploaded.
+func normalizeMarkdown(body string) string {
+	source := []byte(strings.ReplaceAll(body, "\r\n", "\n"))
+	doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+
+	var edits []edit
+	var code [][2]int // fenced and indented code, where whitespace is content
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case ast.KindFencedCodeBlock, ast.KindCodeBlock:
+			if lines := n.Lines(); lines.Len() > 0 {
+				code = append(code, [2]int{lines.At(0).Start, lines.At(lines.Len() - 1).Stop})
+			}
+			return ast.WalkSkipChildren, nil
+		case ast.KindList:
+			edits = append(edits, listMarkerEdits(source, n.(*ast.List))...)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		source = append(source[:e.start:e.start], append(e.repl, source[e.stop:]...)...)
+	}
+	// list marker edits keep their length, so code offsets are still valid
+	return normalizeLines(source, code)
+}
+
+// normalizeText canonicalizes line endings and whitespace only, for html and other non-markdown bodies
+func normalizeText(body string) string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// * and + bullets become -, and the ) of numbered items becomes .
+func listMarkerEdits(source []byte, list *ast.List) []edit {
+	repl := byte('-')
+	if list.IsOrdered() {
+		repl = '.'
+	}
+	if list.Marker == repl {
+		return nil
+	}
+
+	var edits []edit
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		content := item.FirstChild()
+		if content == nil || (content.Kind() != ast.KindParagraph && content.Kind() != ast.KindTextBlock) || content.Lines().Len() == 0 {
+			continue
+		}
+		start := content.Lines().At(0).Start
+		ls := lineStart(source, start)
+		if pos := bytes.LastIndexByte(source[ls:start], list.Marker); pos >= 0 {
+			edits = append(edits, edit{start: ls + pos, stop: ls + pos + 1, repl: []byte{repl}})
+		}
+	}
+	return edits
+}
+
+// outside code, trailing whitespace is dropped except for the two spaces of a hard line break,
+// and runs of blank lines collapse to one
+func normalizeLines(source []byte, code [][2]int) string {
+	inCode := func(pos int) bool {
+		for _, c := range code {
+			if pos >= c[0] && pos < c[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var b strings.Builder
+	pos := 0
+	blank := false
+	for _, line := range strings.SplitAfter(string(source), "\n") {
+		start := pos
+		pos += len(line)
+		if inCode(start) {
+			b.WriteString(line)
+			blank = false
+			continue
+		}
+
+		content := strings.TrimSuffix(line, "\n")
+		trimmed := strings.TrimRight(content, " \t\r")
+		if trimmed == "" {
+			if !blank {
+				b.WriteString("\n")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		b.WriteString(trimmed)
+		if strings.HasSuffix(content, "  ") {
+			b.WriteString("  ")
+		}
+		if len(content) < len(line) {
+			b.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(strings.TrimLeft(b.String(), "\n"), " \t\n")
+}
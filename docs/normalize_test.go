@@ -0,0 +1,87 @@
+package docs
+
+import "testing"
+
+func TestNormalizeMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "line endings",
+			body: "one\r\ntwo\r\n",
+			want: "one\ntwo",
+		},
+		{
+			name: "leading and trailing blank lines",
+			body: "\n\n  \ntext\n\n\n",
+			want: "text",
+		},
+		{
+			name: "trailing whitespace",
+			body: "one \t\ntwo \n",
+			want: "one\ntwo",
+		},
+		{
+			name: "hard break keeps two spaces",
+			body: "one    \ntwo\n",
+			want: "one  \ntwo",
+		},
+		{
+			name: "single trailing space is not a hard break",
+			body: "one \ntwo\n",
+			want: "one\ntwo",
+		},
+		{
+			name: "blank lines collapse outside code",
+			body: "one\n\n\n\ntwo\n",
+			want: "one\n\ntwo",
+		},
+		{
+			name: "fenced code is untouched",
+			body: "```\ncode   \n\n\n\tend\t\n```\n",
+			want: "```\ncode   \n\n\n\tend\t\n```",
+		},
+		{
+			name: "indented code is untouched",
+			body: "text\n\n    code  \n\n\n    more\n",
+			want: "text\n\n    code  \n\n\n    more",
+		},
+		{
+			name: "bullets",
+			body: "* a\n* b\n\n+ c\n",
+			want: "- a\n- b\n\n- c",
+		},
+		{
+			name: "numbered items with parentheses",
+			body: "1) a\n2) b\n",
+			want: "1. a\n2. b",
+		},
+		{
+			name: "numbering is kept",
+			body: "3. a\n7. b\n",
+			want: "3. a\n7. b",
+		},
+		{
+			name: "markers in code are untouched",
+			body: "```\n* a\n1) b\n```\n",
+			want: "```\n* a\n1) b\n```",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeMarkdown(tt.body); got != tt.want {
+				t.Errorf("normalizeMarkdown(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeMarkdownEquivalence(t *testing.T) {
+	local := "# Title\r\n\r\n* one  \r\n  continued\r\n* two\r\n\r\n\r\n1) first\r\n"
+	stored := "# Title\n\n- one  \n  continued\n- two\n\n1. first\n"
+	if normalizeMarkdown(local) != normalizeMarkdown(stored) {
+		t.Errorf("%q and %q normalize differently: %q, %q", local, stored, normalizeMarkdown(local), normalizeMarkdown(stored))
+	}
+}
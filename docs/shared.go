@@ -0,0 +1,79 @@
+package docs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+)
+
+// SharedPaths are the folders of content readme keeps once per project rather than per version.
+// Empty paths leave that kind of content alone.
+type SharedPaths struct {
+	Changelog   string
+	CustomPages string
+	Blocks      string
+}
+
+// Shared is the project-wide content found in the configured folders
+type Shared struct {
+	Changelogs  map[string]EntryMetadata // nil when no changelog folder is configured
+	CustomPages map[string]EntryMetadata // nil when no custom pages folder is configured
+	Blocks      map[string]BlockMetadata // nil when no blocks folder is configured
+	Skipped     []SkippedFile
+}
+
+// WalkShared catalogs the configured changelog, custom pages and blocks folders.
+// Each folder is filtered by its own .readmesyncignore and the default include globs.
+func WalkShared(ctx context.Context, paths SharedPaths) (Shared, error) {
+	var shared Shared
+	skipIn := func(folder string) (func(string, bool) (bool, error), error) {
+		if info, err := os.Stat(folder); err != nil || !info.IsDir() {
+			return nil, xerrors.New(folder + " is not a folder")
+		}
+		filter, err := newFileFilter(folder, WalkOptions{})
+		if err != nil {
+			return nil, xerrors.Errorf(": %w", err)
+		}
+		return func(rel string, isDir bool) (bool, error) {
+			reason, err := filter.skipReason(rel, isDir)
+			if err != nil {
+				return false, xerrors.Errorf(": %w", err)
+			}
+			if reason != "" {
+				shared.Skipped = append(shared.Skipped, SkippedFile{Path: filepath.Join(folder, rel), Reason: reason})
+			}
+			return reason != "", nil
+		}, nil
+	}
+
+	if paths.Changelog != "" {
+		skip, err := skipIn(paths.Changelog)
+		if err != nil {
+			return Shared{}, xerrors.Errorf(": %w", err)
+		}
+		if shared.Changelogs, err = walkEntries(paths.Changelog, skip); err != nil {
+			return Shared{}, xerrors.Errorf(": %w", err)
+		}
+	}
+	if paths.CustomPages != "" {
+		skip, err := skipIn(paths.CustomPages)
+		if err != nil {
+			return Shared{}, xerrors.Errorf(": %w", err)
+		}
+		if shared.CustomPages, err = walkEntries(paths.CustomPages, skip); err != nil {
+			return Shared{}, xerrors.Errorf(": %w", err)
+		}
+	}
+	if paths.Blocks != "" {
+		skip, err := skipIn(paths.Blocks)
+		if err != nil {
+			return Shared{}, xerrors.Errorf(": %w", err)
+		}
+		if shared.Blocks, err = walkBlocks(paths.Blocks, skip); err != nil {
+			return Shared{}, xerrors.Errorf(": %w", err)
+		}
+	}
+	return shared, nil
+}
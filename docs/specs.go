@@ -0,0 +1,276 @@
+package docs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rolflewis/readme-sync/logging"
+	"github.com/rolflewis/readme-sync/readme"
+	"github.com/rolflewis/readme-sync/report"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+// SpecStateFile remembers the ids and content hashes of uploaded specifications between runs,
+// since readme offers no way to tell whether a specification changed without uploading it.
+// It is kept next to the configuration file.
+const SpecStateFile = ".readme-sync-state.json"
+
+type SpecMetadata struct {
+	Path      string
+	StatePath string // Path relative to the state file, which the state remembers it by
+	Id        string // readme specification id, looked up by title or created on first upload when empty
+	Project   string // readme project, part of the state key when several projects are synced
+}
+
+type specRecord struct {
+	Id   string `json:"id"`
+	Hash string `json:"hash"`
+}
+
+// SpecState maps project, version and spec path to the last upload
+type SpecState map[string]specRecord
+
+func specStateKey(project, version, path string) string {
+	if project != "" {
+		return project + ":" + version + ":" + path
+	}
+	return version + ":" + path
+}
+
+func LoadSpecState(path string) (SpecState, error) {
+	contents, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return make(SpecState), nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+
+	state := make(SpecState)
+	if err := json.Unmarshal(contents, &state); err != nil {
+		return nil, xerrors.Errorf("%v: %w", path, err)
+	}
+	return state, nil
+}
+
+func (s SpecState) Save(path string) error {
+	contents, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	if err := os.WriteFile(path, append(contents, '\n'), 0o644); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+// ValidateSpec checks that data is a structurally sound openapi 3 or swagger 2 document
+// with resolvable local references, so broken specs fail before they reach readme
+func ValidateSpec(data []byte) error {
+	if _, err := validateSpec(data); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+// validateSpec is ValidateSpec, returning the title of the specification
+func validateSpec(data []byte) (string, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+	if raw == nil {
+		return "", xerrors.New("specification is empty")
+	}
+	root, ok := normalizeSpec(raw).(map[string]any)
+	if !ok {
+		return "", xerrors.New("specification must be a mapping")
+	}
+
+	// unquoted versions such as openapi: 3.1 or version: 1.0 are numbers in yaml
+	openapi, swagger := specScalar(root["openapi"]), specScalar(root["swagger"])
+	if openapi != "3" && !strings.HasPrefix(openapi, "3.") && swagger != "2" && swagger != "2.0" {
+		return "", xerrors.New("specification must declare openapi 3.x or swagger 2.0")
+	}
+
+	info, _ := root["info"].(map[string]any)
+	title := specScalar(info["title"])
+	if title == "" {
+		return "", xerrors.New("info.title is required")
+	}
+	if specScalar(info["version"]) == "" {
+		return "", xerrors.New("info.version is required")
+	}
+	if _, ok := root["paths"].(map[string]any); !ok {
+		return "", xerrors.New("paths is required")
+	}
+
+	var refErrs []string
+	walkSpecRefs(root, func(ref string) {
+		if strings.HasPrefix(ref, "#/") && !resolvePointer(root, ref) {
+			refErrs = append(refErrs, ref)
+		}
+	})
+	if len(refErrs) > 0 {
+		return "", xerrors.New("unresolved references: " + strings.Join(refErrs, ", "))
+	}
+	return title, nil
+}
+
+// specScalar returns a scalar as text, or an empty string for mappings, sequences and null
+func specScalar(v any) string {
+	switch v.(type) {
+	case nil, map[string]any, []any:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// normalizeSpec turns the map[any]any yaml produces for mappings with keys that are not strings,
+// such as unquoted status codes like 200, into map[string]any so that they are walked like the rest
+func normalizeSpec(node any) any {
+	switch n := node.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(n))
+		for key, value := range n {
+			m[fmt.Sprint(key)] = normalizeSpec(value)
+		}
+		return m
+	case map[string]any:
+		for key, value := range n {
+			n[key] = normalizeSpec(value)
+		}
+		return n
+	case []any:
+		for i, value := range n {
+			n[i] = normalizeSpec(value)
+		}
+		return n
+	}
+	return node
+}
+
+func walkSpecRefs(node any, visit func(string)) {
+	switch n := node.(type) {
+	case map[string]any:
+		for key, value := range n {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				visit(ref)
+				continue
+			}
+			walkSpecRefs(value, visit)
+		}
+	case []any:
+		for _, value := range n {
+			walkSpecRefs(value, visit)
+		}
+	}
+}
+
+// resolvePointer follows a local json pointer such as #/components/schemas/Pet
+func resolvePointer(root map[string]any, ref string) bool {
+	var node any = root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]any)
+		if !ok {
+			return false
+		}
+		if node, ok = m[token]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ProcessSpec validates and uploads the specification if it changed since the last upload recorded in state
+func ProcessSpec(ctx context.Context, c *readme.Client, version string, metadata SpecMetadata, state SpecState) (report.Action, error) {
+	log := logging.FromContext(ctx)
+
+	data, err := os.ReadFile(metadata.Path)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+
+	title, err := validateSpec(data)
+	if err != nil {
+		return "", xerrors.Errorf("specification \"%v\": %w", metadata.Path, err)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	key := specStateKey(metadata.Project, version, metadata.StatePath)
+	record := state[key]
+
+	id := metadata.Id
+	if id == "" {
+		id = record.Id
+	}
+	if id != "" && record.Id == id && record.Hash == hash {
+		log.Info("No change to specification", "path", metadata.Path, "id", id)
+		return report.Unchanged, nil
+	}
+
+	// without a matching record the specification may already be in readme, e.g. on a fresh checkout,
+	// and creating it again would add a second copy
+	existing, err := c.GetSpecs(ctx)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+	if id, err = matchSpec(existing, metadata.Id, record.Id, title); err != nil {
+		return "", xerrors.Errorf("specification \"%v\": %w", metadata.Path, err)
+	}
+
+	var action report.Action
+	if id == "" {
+		action = report.Created
+		log.Info("Creating specification", "path", metadata.Path)
+		if id, err = c.CreateSpec(ctx, metadata.Path, data); err != nil {
+			return "", xerrors.Errorf(": %w", err)
+		}
+		log.Info("Created specification", "path", metadata.Path, "id", id)
+	} else {
+		action = report.Updated
+		log.Info("Updating specification", "path", metadata.Path, "id", id)
+		if err := c.UpdateSpec(ctx, id, metadata.Path, data); err != nil {
+			return "", xerrors.Errorf(": %w", err)
+		}
+	}
+
+	state[key] = specRecord{Id: id, Hash: hash}
+	return action, nil
+}
+
+// matchSpec picks the specification in readme to update: the configured id, which must exist,
+// else the remembered id if it still exists, else the only one with the same title.
+// It returns an empty id when the specification has to be created.
+func matchSpec(existing []readme.Spec, configured, remembered, title string) (string, error) {
+	var titled []string
+	for _, spec := range existing {
+		if configured != "" && spec.Id == configured {
+			return configured, nil
+		}
+		if configured == "" && remembered != "" && spec.Id == remembered {
+			return remembered, nil
+		}
+		if spec.Title == title {
+			titled = append(titled, spec.Id)
+		}
+	}
+
+	switch {
+	case configured != "":
+		return "", xerrors.New(fmt.Sprintf("no specification with id \"%v\" found in readme", configured))
+	case len(titled) > 1:
+		return "", xerrors.New(fmt.Sprintf("several specifications titled \"%v\" found in readme (%v), set the id of the one to update", title, strings.Join(titled, ", ")))
+	case len(titled) == 1:
+		return titled[0], nil
+	}
+	return "", nil
+}
@@ -0,0 +1,198 @@
+package docs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rolflewis/readme-sync/readme"
+)
+
+func TestMatchSpec(t *testing.T) {
+	existing := []readme.Spec{
+		{Id: "a", Title: "Pets"},
+		{Id: "b", Title: "Stores"},
+		{Id: "c", Title: "Stores"},
+	}
+	tests := []struct {
+		name       string
+		configured string
+		remembered string
+		title      string
+		want       string
+		wantErr    bool
+	}{
+		{name: "configured id", configured: "b", title: "Pets", want: "b"},
+		{name: "configured id missing", configured: "x", title: "Pets", wantErr: true},
+		{name: "remembered id", remembered: "c", title: "Stores", want: "c"},
+		{name: "remembered id gone falls back to title", remembered: "x", title: "Pets", want: "a"},
+		{name: "title", title: "Pets", want: "a"},
+		{name: "ambiguous title", title: "Stores", wantErr: true},
+		{name: "new specification", title: "Users", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchSpec(existing, tt.configured, tt.remembered, tt.title)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("matchSpec: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("matchSpec = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr string
+	}{
+		{
+			name: "openapi 3 yaml",
+			spec: `openapi: "3.0.3"
+info:
+  title: Pets
+  version: "1.0"
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+components:
+  schemas:
+    Pet:
+      type: object
+`,
+		},
+		{
+			name: "openapi 3 json",
+			spec: `{"openapi": "3.1.0", "info": {"title": "Pets", "version": "1.0"}, "paths": {}}`,
+		},
+		{
+			name: "swagger 2 yaml",
+			spec: `swagger: "2.0"
+info:
+  title: Pets
+  version: "1.0"
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          schema:
+            $ref: "#/definitions/Pet"
+definitions:
+  Pet:
+    type: object
+`,
+		},
+		{
+			name: "swagger 2 json",
+			spec: `{"swagger": "2.0", "info": {"title": "Pets", "version": "1.0"}, "paths": {}}`,
+		},
+		{
+			name: "unquoted versions",
+			spec: "openapi: 3.1\ninfo:\n  title: Pets\n  version: 1.0\npaths: {}\n",
+		},
+		{
+			name: "unquoted swagger version",
+			spec: "swagger: 2.0\ninfo:\n  title: Pets\n  version: 1\npaths: {}\n",
+		},
+		{
+			name: "unquoted status codes are searched for references",
+			spec: `openapi: 3.0.0
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      responses:
+        200:
+          $ref: "#/components/responses/Missing"
+`,
+			wantErr: "#/components/responses/Missing",
+		},
+		{
+			name: "escaped pointer tokens",
+			spec: `openapi: 3.0.0
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets/{id}:
+    get: {}
+components:
+  links:
+    self:
+      $ref: "#/paths/~1pets~1{id}/get"
+`,
+		},
+		{
+			name:    "unresolved reference",
+			spec:    "openapi: 3.0.0\ninfo: {title: Pets, version: 1.0.0}\npaths:\n  /pets:\n    $ref: \"#/components/pathItems/Pets\"\n",
+			wantErr: "unresolved references: #/components/pathItems/Pets",
+		},
+		{
+			name:    "other version",
+			spec:    "openapi: 2.0\ninfo: {title: Pets, version: 1.0.0}\npaths: {}\n",
+			wantErr: "openapi 3.x or swagger 2.0",
+		},
+		{
+			name:    "no version",
+			spec:    "info: {title: Pets, version: 1.0.0}\npaths: {}\n",
+			wantErr: "openapi 3.x or swagger 2.0",
+		},
+		{
+			name:    "missing title",
+			spec:    "openapi: 3.0.0\ninfo: {version: 1.0.0}\npaths: {}\n",
+			wantErr: "info.title is required",
+		},
+		{
+			name:    "missing info version",
+			spec:    "openapi: 3.0.0\ninfo: {title: Pets}\npaths: {}\n",
+			wantErr: "info.version is required",
+		},
+		{
+			name:    "missing paths",
+			spec:    "openapi: 3.0.0\ninfo: {title: Pets, version: 1.0.0}\n",
+			wantErr: "paths is required",
+		},
+		{
+			name:    "empty",
+			spec:    "",
+			wantErr: "specification is empty",
+		},
+		{
+			name:    "not a mapping",
+			spec:    "- openapi\n",
+			wantErr: "must be a mapping",
+		},
+		{
+			name:    "invalid syntax",
+			spec:    "{\"openapi\": ",
+			wantErr: "yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSpec([]byte(tt.spec))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateSpec: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
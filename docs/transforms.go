@@ -0,0 +1,196 @@
+package docs
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+	"golang.org/x/xerrors"
+)
+
+// Markdown transforms that can be enabled in the configuration file
+const (
+	TransformCallouts = "callouts"  // github admonitions to readme callouts
+	TransformCodeTabs = "code-tabs" // labelled code blocks to readme code tabs
+)
+
+// transforms locate constructs with the goldmark AST, but rewrite the original source
+// so that everything else in the doc is passed through byte for byte
+type transform func(source []byte, doc ast.Node) []edit
+
+var transforms = map[string]transform{
+	TransformCallouts: calloutEdits,
+	TransformCodeTabs: codeTabEdits,
+}
+
+type edit struct {
+	start int
+	stop  int
+	repl  []byte
+}
+
+func ValidateTransforms(names []string) error {
+	for _, name := range names {
+		if _, found := transforms[name]; !found {
+			return xerrors.New("unknown markdown transform: " + name)
+		}
+	}
+	return nil
+}
+
+// applyTransforms runs the named transforms over body in order
+func applyTransforms(body []byte, names []string) ([]byte, error) {
+	for _, name := range names {
+		t, found := transforms[name]
+		if !found {
+			return nil, xerrors.New("unknown markdown transform: " + name)
+		}
+
+		doc := goldmark.DefaultParser().Parse(text.NewReader(body))
+		edits := t(body, doc)
+
+		// apply back to front so earlier offsets stay valid
+		sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+		for _, e := range edits {
+			body = append(body[:e.start:e.start], append(e.repl, body[e.stop:]...)...)
+		}
+	}
+	return body, nil
+}
+
+var admonitionPattern = regexp.MustCompile(`^\[!(NOTE|TIP|IMPORTANT|WARNING|CAUTION)\][ \t]*(.*)$`)
+
+// readme picks the callout style from the leading emoji
+var calloutEmoji = map[string]string{
+	"NOTE":      "📘",
+	"TIP":       "👍",
+	"IMPORTANT": "📘",
+	"WARNING":   "🚧",
+	"CAUTION":   "❗️",
+}
+
+// > [!WARNING] Optional title  =>  > 🚧 Optional title
+func calloutEdits(source []byte, doc ast.Node) []edit {
+	var edits []edit
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Kind() != ast.KindBlockquote {
+			return ast.WalkContinue, nil
+		}
+
+		para := n.FirstChild()
+		if para == nil || para.Kind() != ast.KindParagraph || para.Lines().Len() == 0 {
+			return ast.WalkContinue, nil
+		}
+
+		seg := para.Lines().At(0)
+		line := bytes.TrimRight(seg.Value(source), "\r\n")
+		match := admonitionPattern.FindSubmatch(line)
+		if match == nil {
+			return ast.WalkContinue, nil
+		}
+
+		kind := string(match[1])
+		title := strings.TrimSpace(string(match[2]))
+		if title == "" {
+			title = kind[:1] + strings.ToLower(kind[1:])
+		}
+
+		edits = append(edits, edit{
+			start: seg.Start,
+			stop:  seg.Start + len(line),
+			repl:  []byte(calloutEmoji[kind] + " " + title),
+		})
+		return ast.WalkContinue, nil
+	})
+	return edits
+}
+
+var codeLabelPattern = regexp.MustCompile(`^(\S+)\s+(?:\[([^\]]+)\]|title="([^"]+)")\s*$`)
+
+// Adjacent fenced code blocks with labels (```js [Node] or ```js title="Node") become a single
+// set of readme code tabs, which requires the blocks to have no blank lines between them and
+// the tab name to follow the language.
+func codeTabEdits(source []byte, doc ast.Node) []edit {
+	var edits []edit
+	var group []*ast.FencedCodeBlock
+
+	flush := func() {
+		if len(group) > 1 {
+			for i, block := range group {
+				match := codeLabelPattern.FindSubmatch(block.Info.Segment.Value(source))
+				label := match[2]
+				if len(label) == 0 {
+					label = match[3]
+				}
+				edits = append(edits, edit{
+					start: block.Info.Segment.Start,
+					stop:  block.Info.Segment.Stop,
+					repl:  append(append(append([]byte{}, match[1]...), ' '), label...),
+				})
+
+				if i > 0 {
+					prevEnd, ok := closingFenceEnd(source, group[i-1])
+					if ok {
+						edits = append(edits, edit{
+							start: prevEnd,
+							stop:  lineStart(source, block.Info.Segment.Start),
+						})
+					}
+				}
+			}
+		}
+		group = nil
+	}
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Kind() != ast.KindFencedCodeBlock {
+			return ast.WalkContinue, nil
+		}
+
+		block := n.(*ast.FencedCodeBlock)
+		labelled := block.Info != nil && codeLabelPattern.Match(block.Info.Segment.Value(source))
+		if !labelled {
+			flush()
+			return ast.WalkSkipChildren, nil
+		}
+
+		if len(group) > 0 && group[len(group)-1].NextSibling() != n {
+			flush()
+		}
+		group = append(group, block)
+		return ast.WalkSkipChildren, nil
+	})
+	flush()
+
+	return edits
+}
+
+func lineStart(source []byte, pos int) int {
+	return bytes.LastIndexByte(source[:pos], '\n') + 1
+}
+
+func lineEnd(source []byte, pos int) int {
+	if end := bytes.IndexByte(source[pos:], '\n'); end >= 0 {
+		return pos + end + 1
+	}
+	return len(source)
+}
+
+// returns the offset just past the closing fence line, if the block is closed
+func closingFenceEnd(source []byte, block *ast.FencedCodeBlock) (int, bool) {
+	pos := lineEnd(source, block.Info.Segment.Stop)
+	if lines := block.Lines(); lines.Len() > 0 {
+		pos = lines.At(lines.Len() - 1).Stop
+	}
+
+	end := lineEnd(source, pos)
+	fence := bytes.TrimSpace(source[pos:end])
+	if !bytes.HasPrefix(fence, []byte("```")) && !bytes.HasPrefix(fence, []byte("~~~")) {
+		return 0, false
+	}
+	return end, true
+}
@@ -0,0 +1,109 @@
+package docs
+
+import "testing"
+
+func TestCalloutsTransform(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"note", "> [!NOTE]\n> Text\n", "> 📘 Note\n> Text\n"},
+		{"tip", "> [!TIP]\n> Text\n", "> 👍 Tip\n> Text\n"},
+		{"important", "> [!IMPORTANT]\n> Text\n", "> 📘 Important\n> Text\n"},
+		{"warning", "> [!WARNING]\n> Text\n", "> 🚧 Warning\n> Text\n"},
+		{"caution", "> [!CAUTION]\n> Text\n", "> ❗️ Caution\n> Text\n"},
+		{"custom title", "> [!WARNING] Breaking change\n> Text\n", "> 🚧 Breaking change\n> Text\n"},
+		{"crlf", "> [!NOTE]\r\n> Text\r\n", "> 📘 Note\r\n> Text\r\n"},
+		{"unknown kind", "> [!DANGER]\n> Text\n", "> [!DANGER]\n> Text\n"},
+		{"plain quote", "> Just a quote\n", "> Just a quote\n"},
+		{"not at the start of the quote", "> Text\n> [!NOTE]\n", "> Text\n> [!NOTE]\n"},
+		{"in code", "```\n> [!NOTE]\n```\n", "```\n> [!NOTE]\n```\n"},
+		{"nested in a list", "- item\n\n  > [!TIP]\n  > Text\n", "- item\n\n  > 👍 Tip\n  > Text\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyTransforms([]byte(tt.body), []string{TransformCallouts})
+			if err != nil {
+				t.Fatalf("applyTransforms: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeTabsTransform(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "bracket labels",
+			body: "```js [Node]\na\n```\n```py [Python]\nb\n```\n",
+			want: "```js Node\na\n```\n```py Python\nb\n```\n",
+		},
+		{
+			name: "title labels",
+			body: "```js title=\"Node\"\na\n```\n```py title=\"Python\"\nb\n```\n",
+			want: "```js Node\na\n```\n```py Python\nb\n```\n",
+		},
+		{
+			name: "blank lines between labelled blocks are removed",
+			body: "```js [Node]\na\n```\n\n\n```py [Python]\nb\n```\n",
+			want: "```js Node\na\n```\n```py Python\nb\n```\n",
+		},
+		{
+			name: "three tabs",
+			body: "```js [Node]\na\n```\n\n```py [Python]\nb\n```\n\n```go [Go]\nc\n```\n",
+			want: "```js Node\na\n```\n```py Python\nb\n```\n```go Go\nc\n```\n",
+		},
+		{
+			name: "single labelled block is left alone",
+			body: "```js [Node]\na\n```\n",
+			want: "```js [Node]\na\n```\n",
+		},
+		{
+			name: "unlabelled blocks are left alone",
+			body: "```js\na\n```\n\n```py\nb\n```\n",
+			want: "```js\na\n```\n\n```py\nb\n```\n",
+		},
+		{
+			name: "unlabelled block splits groups",
+			body: "```js [Node]\na\n```\n\n```sh\nc\n```\n\n```py [Python]\nb\n```\n",
+			want: "```js [Node]\na\n```\n\n```sh\nc\n```\n\n```py [Python]\nb\n```\n",
+		},
+		{
+			name: "non-adjacent blocks are left alone",
+			body: "```js [Node]\na\n```\n\nText between.\n\n```py [Python]\nb\n```\n",
+			want: "```js [Node]\na\n```\n\nText between.\n\n```py [Python]\nb\n```\n",
+		},
+		{
+			name: "tilde fences",
+			body: "~~~js [Node]\na\n~~~\n\n~~~py [Python]\nb\n~~~\n",
+			want: "~~~js Node\na\n~~~\n~~~py Python\nb\n~~~\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyTransforms([]byte(tt.body), []string{TransformCodeTabs})
+			if err != nil {
+				t.Fatalf("applyTransforms: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTransformsUnknown(t *testing.T) {
+	if _, err := applyTransforms([]byte("text"), []string{"mermaid"}); err == nil {
+		t.Error("expected an error for an unknown transform")
+	}
+	if err := ValidateTransforms([]string{TransformCallouts, "mermaid"}); err == nil {
+		t.Error("expected ValidateTransforms to reject an unknown transform")
+	}
+}
@@ -0,0 +1,100 @@
+package docs
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+	"golang.org/x/xerrors"
+)
+
+// matches {{ name }} and the escaped \{{ name }} - the leading < of include directives keeps the two from overlapping
+var variablePattern = regexp.MustCompile(`(\\?)\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// substituteVariables replaces every {{ name }} reference in text with its value from vars.
+// An escaped \{{ name }} is kept as {{ name }}. All undefined names are reported together
+// rather than failing on the first one.
+func substituteVariables(text string, vars map[string]string) (string, error) {
+	return substitute(text, vars, nil)
+}
+
+// substituteMarkdownVariables is substituteVariables for markdown bodies. Code spans and blocks
+// are left alone, so samples in Jinja, Handlebars or Go templates are shown as written.
+func substituteMarkdownVariables(body string, vars map[string]string) (string, error) {
+	return substitute(body, vars, codeRanges([]byte(body)))
+}
+
+func substitute(text string, vars map[string]string, skip [][2]int) (string, error) {
+	missing := make(map[string]struct{})
+	var b strings.Builder
+	last := 0
+	for _, match := range variablePattern.FindAllStringSubmatchIndex(text, -1) {
+		start, stop := match[0], match[1]
+		if inRanges(skip, start) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		last = stop
+
+		if match[3] > match[2] { // escaped
+			b.WriteString(text[match[3]:stop])
+			continue
+		}
+		name := text[match[4]:match[5]]
+		value, ok := vars[name]
+		if !ok {
+			missing[name] = struct{}{}
+		}
+		b.WriteString(value)
+	}
+	b.WriteString(text[last:])
+
+	if len(missing) > 0 {
+		var names []string
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", xerrors.New("undefined variables: " + strings.Join(names, ", "))
+	}
+	return b.String(), nil
+}
+
+// codeRanges returns the byte ranges of the code spans and code blocks in a markdown source
+func codeRanges(source []byte) [][2]int {
+	doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+
+	var ranges [][2]int
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case ast.KindFencedCodeBlock, ast.KindCodeBlock:
+			if lines := n.Lines(); lines.Len() > 0 {
+				ranges = append(ranges, [2]int{lines.At(0).Start, lines.At(lines.Len() - 1).Stop})
+			}
+			return ast.WalkSkipChildren, nil
+		case ast.KindCodeSpan:
+			first, last := n.FirstChild(), n.LastChild()
+			if first != nil && first.Kind() == ast.KindText && last.Kind() == ast.KindText {
+				ranges = append(ranges, [2]int{first.(*ast.Text).Segment.Start, last.(*ast.Text).Segment.Stop})
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return ranges
+}
+
+func inRanges(ranges [][2]int, pos int) bool {
+	for _, r := range ranges {
+		if pos >= r[0] && pos < r[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package docs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubstituteVariables(t *testing.T) {
+	vars := map[string]string{"product": "Acme", "version": "2.0", "empty": ""}
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr string
+	}{
+		{name: "defined", text: "{{product}} {{ version }}", want: "Acme 2.0"},
+		{name: "empty value", text: "a{{ empty }}b", want: "ab"},
+		{name: "no references", text: "plain { text }", want: "plain { text }"},
+		{name: "undefined names reported together", text: "{{ b }} {{ a }} {{ b }}", wantErr: "undefined variables: a, b"},
+		{name: "escaped", text: `\{{ product }} is {{ product }}`, want: "{{ product }} is Acme"},
+		{name: "escaped undefined", text: `\{{ missing }}`, want: "{{ missing }}"},
+		{name: "not a name", text: "{{ 1st }}", want: "{{ 1st }}"},
+		{name: "include shortcode untouched", text: `{{< include "a.md" >}}`, want: `{{< include "a.md" >}}`},
+		{name: "code is substituted outside markdown", text: "`{{ product }}`", want: "`Acme`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := substituteVariables(tt.text, vars)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("substituteVariables: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubstituteMarkdownVariables(t *testing.T) {
+	vars := map[string]string{"product": "Acme"}
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "paragraph",
+			body: "Welcome to {{ product }}.\n",
+			want: "Welcome to Acme.\n",
+		},
+		{
+			name: "code span",
+			body: "Use `{{ user.name }}` in {{ product }} templates.\n",
+			want: "Use `{{ user.name }}` in Acme templates.\n",
+		},
+		{
+			name: "double backtick code span",
+			body: "``{{ a }} ` {{ b }}`` and {{ product }}\n",
+			want: "``{{ a }} ` {{ b }}`` and Acme\n",
+		},
+		{
+			name: "fenced block",
+			body: "{{ product }}\n\n```jinja\n{{ user.name }}\n\n{{ missing }}\n```\n",
+			want: "Acme\n\n```jinja\n{{ user.name }}\n\n{{ missing }}\n```\n",
+		},
+		{
+			name: "indented block",
+			body: "Text\n\n    {{ missing }}\n",
+			want: "Text\n\n    {{ missing }}\n",
+		},
+		{
+			name: "escape outside code",
+			body: "Write \\{{ product }} to get {{ product }}.\n",
+			want: "Write {{ product }} to get Acme.\n",
+		},
+		{
+			name: "backslash in code is kept",
+			body: "`\\{{ product }}`\n",
+			want: "`\\{{ product }}`\n",
+		},
+		{
+			name:    "undefined outside code",
+			body:    "`{{ fine }}` {{ missing }}\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := substituteMarkdownVariables(tt.body, vars)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("substituteMarkdownVariables: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
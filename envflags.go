@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/rolflewis/readme-sync/config"
+	"golang.org/x/xerrors"
+)
+
+const defaultEnvFile = ".env"
+
+// envFlags choose where the environment and api key come from
+type envFlags struct {
+	files    listFlags
+	keyStdin bool
+	stdinKey []byte // stdin can only be read once, watch loads the configuration again
+	stdinErr error
+	loaded   map[string]struct{} // variables set from the files rather than the environment
+}
+
+func (e *envFlags) register(fs *flag.FlagSet) {
+	fs.Var(&e.files, "env-file", "load environment variables from this file, defaults to .env when it exists (repeatable)")
+	fs.BoolVar(&e.keyStdin, "apikey-stdin", false, "read the api key from stdin instead of README_APIKEY or README_APIKEY_FILE")
+}
+
+// load reads the environment files. Variables already set in the environment are kept,
+// so CI secrets win over a stray .env file, and earlier files win over later ones.
+// Loading again replaces the variables set by the previous load.
+func (e *envFlags) load() error {
+	values := make(map[string]string)
+	for _, path := range e.paths() {
+		file, err := godotenv.Read(path)
+		if len(e.files) == 0 && os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		for name, value := range file {
+			if _, set := values[name]; !set {
+				values[name] = value
+			}
+		}
+	}
+
+	for name := range e.loaded {
+		if _, kept := values[name]; !kept {
+			os.Unsetenv(name)
+			delete(e.loaded, name)
+		}
+	}
+	if e.loaded == nil {
+		e.loaded = make(map[string]struct{})
+	}
+	for name, value := range values {
+		if _, fromFile := e.loaded[name]; !fromFile {
+			if _, set := os.LookupEnv(name); set {
+				continue
+			}
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		e.loaded[name] = struct{}{}
+	}
+	return nil
+}
+
+// paths returns the environment files that may have been loaded
+func (e *envFlags) paths() []string {
+	if len(e.files) == 0 {
+		return []string{defaultEnvFile}
+	}
+	return e.files
+}
+
+// keyReader is where the api key is read from, nil for the environment
+func (e *envFlags) keyReader() io.Reader {
+	if !e.keyStdin {
+		return nil
+	}
+	if e.stdinKey == nil && e.stdinErr == nil {
+		e.stdinKey, e.stdinErr = io.ReadAll(os.Stdin)
+	}
+	if e.stdinErr != nil {
+		return errReader{e.stdinErr}
+	}
+	return bytes.NewReader(e.stdinKey)
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+func (e *envFlags) key() (string, error) {
+	key, err := config.LoadKey(e.keyReader())
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+	return key, nil
+}
@@ -5,13 +5,15 @@ go 1.19
 require golang.org/x/xerrors v0.0.0-20220907171357-04be3eba64a2
 
 require (
-	github.com/BurntSushi/toml v0.3.1 // indirect
-	github.com/adrg/frontmatter v0.2.0 // indirect
 	github.com/gosimple/unidecode v1.0.1 // indirect
+	golang.org/x/sys v0.10.0 // indirect
 	gopkg.in/yaml.v2 v2.3.0 // indirect
 )
 
 require (
+	github.com/BurntSushi/toml v0.3.1
+	github.com/adrg/frontmatter v0.2.0
+	github.com/fsnotify/fsnotify v1.5.1
 	github.com/google/go-cmp v0.5.9
 	github.com/gosimple/slug v1.13.1
 	github.com/joho/godotenv v1.4.0
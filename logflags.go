@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/rolflewis/readme-sync/logging"
+	"golang.org/x/xerrors"
+)
+
+// logFlags are the verbosity and format flags shared by every command
+type logFlags struct {
+	verbose bool
+	quiet   bool
+	format  string
+	log     *logging.Logger // set by apply
+}
+
+func (l *logFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&l.verbose, "v", false, "log debug output, including every http request and response")
+	fs.BoolVar(&l.quiet, "q", false, "only log warnings and errors")
+	fs.StringVar(&l.format, "log-format", logging.FormatText, "log format, text or json")
+}
+
+// apply builds the logger from the parsed flags and returns a context carrying it
+func (l *logFlags) apply(ctx context.Context) (context.Context, error) {
+	if l.verbose && l.quiet {
+		return ctx, xerrors.New("-v and -q cannot be combined")
+	}
+
+	level := logging.LevelInfo
+	if l.verbose {
+		level = logging.LevelDebug
+	} else if l.quiet {
+		level = logging.LevelWarn
+	}
+
+	// stdout is kept for the data commands print, such as config show and version list
+	log, err := logging.New(os.Stderr, level, l.format)
+	if err != nil {
+		return ctx, xerrors.Errorf(": %w", err)
+	}
+	l.log = log
+	return logging.NewContext(ctx, log), nil
+}
+
+// logger returns the configured logger, or the default one when flags were never applied
+func (l *logFlags) logger() *logging.Logger {
+	if l.log == nil {
+		return logging.Default()
+	}
+	return l.log
+}
+
+// color reports whether logs may be colorized: text logs to a terminal, unless NO_COLOR is set
+func (l *logFlags) color() bool {
+	if l.format == logging.FormatJSON || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,160 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Level orders messages by importance, mirroring the levels of log/slog
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "INFO"
+	}
+}
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Logger writes leveled messages with key/value attributes, in the style of log/slog.
+// It is safe for concurrent use.
+type Logger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	level  Level
+	format string
+}
+
+func New(w io.Writer, level Level, format string) (*Logger, error) {
+	if format == "" {
+		format = FormatText
+	}
+	if format != FormatText && format != FormatJSON {
+		return nil, xerrors.New("log format must be text or json")
+	}
+	return &Logger{w: w, level: level, format: format}, nil
+}
+
+// Default logs info and above as text to stderr, leaving stdout to the data commands print
+func Default() *Logger {
+	return &Logger{w: os.Stderr, level: LevelInfo, format: FormatText}
+}
+
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.level
+}
+
+func (l *Logger) Debug(msg string, args ...any) { l.log(LevelDebug, msg, args) }
+func (l *Logger) Info(msg string, args ...any)  { l.log(LevelInfo, msg, args) }
+func (l *Logger) Warn(msg string, args ...any)  { l.log(LevelWarn, msg, args) }
+func (l *Logger) Error(msg string, args ...any) { l.log(LevelError, msg, args) }
+
+// args alternate between keys and values, a trailing key without a value is logged as !BADKEY
+func (l *Logger) log(level Level, msg string, args []any) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	var line []byte
+	if l.format == FormatJSON {
+		entry := map[string]any{
+			"time":  time.Now().Format(time.RFC3339Nano),
+			"level": level.String(),
+			"msg":   msg,
+		}
+		for i := 0; i < len(args); i += 2 {
+			key, value := attr(args, i)
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			entry[key] = value
+		}
+		encoded, err := json.Marshal(entry)
+		if err != nil {
+			encoded, _ = json.Marshal(map[string]any{"level": level.String(), "msg": msg, "error": err.Error()})
+		}
+		line = append(encoded, '\n')
+	} else {
+		var b strings.Builder
+		if level != LevelInfo {
+			b.WriteString(level.String() + " ")
+		}
+		b.WriteString(msg)
+		var blocks []string
+		for i := 0; i < len(args); i += 2 {
+			key, value := attr(args, i)
+			text := fmt.Sprint(value)
+			if strings.Contains(text, "\n") {
+				blocks = append(blocks, text) // multi-line values such as diffs are kept readable below the message
+				continue
+			}
+			if strings.ContainsAny(text, " \t\"=") || text == "" {
+				text = fmt.Sprintf("%q", text)
+			}
+			b.WriteString(" " + key + "=" + text)
+		}
+		b.WriteString("\n")
+		for _, block := range blocks {
+			for _, line := range strings.Split(strings.TrimSuffix(block, "\n"), "\n") {
+				b.WriteString("    " + line + "\n")
+			}
+		}
+		line = []byte(b.String())
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w.Write(line)
+}
+
+func attr(args []any, i int) (string, any) {
+	key, ok := args[i].(string)
+	if !ok {
+		return "!BADKEY", args[i]
+	}
+	if i+1 >= len(args) {
+		return "!BADKEY", key
+	}
+	return key, args[i+1]
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying l, for the packages that log on behalf of a command
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or Default when there is none
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	return Default()
+}
@@ -4,101 +4,461 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
 	"github.com/rolflewis/readme-sync/config"
 	"github.com/rolflewis/readme-sync/docs"
+	"github.com/rolflewis/readme-sync/logging"
 	"github.com/rolflewis/readme-sync/readme"
+	"github.com/rolflewis/readme-sync/report"
 	"golang.org/x/xerrors"
 )
 
 func main() {
+	lf := &logFlags{}
 	if len(os.Args) < 2 {
-		fmt.Println("No arguments provided")
-		return
+		lf.logger().Error("No arguments provided")
+		os.Exit(1)
 	}
 
-	if err := godotenv.Load(); err != nil {
-		fmt.Printf("%+v", err)
-		return
+	var err error
+	switch os.Args[1] {
+	case "version":
+		err = versionCmd(context.Background(), lf, os.Args[2:])
+	case "config":
+		err = configCmd(context.Background(), lf, os.Args[2:])
+	case "watch":
+		err = watchCmd(context.Background(), lf, os.Args[2:])
+	default:
+		flags := flag.NewFlagSet("", flag.ContinueOnError)
+		err = walk(context.Background(), flags, lf, os.Args[1:])
 	}
+	if err != nil {
+		lf.logger().Error(fmt.Sprintf("%+v", err))
+		os.Exit(1) // so that ci fails when items failed to sync
+	}
+}
 
-	flags := flag.NewFlagSet("", flag.ContinueOnError)
-	if err := walk(context.Background(), flags, os.Args[1:]); err != nil {
-		fmt.Printf("%+v", err)
-		return
+// varFlags collects repeated -var name=value flags
+type varFlags map[string]string
+
+func (v varFlags) String() string {
+	var pairs []string
+	for name, value := range v {
+		pairs = append(pairs, name+"="+value)
 	}
+	return strings.Join(pairs, ",")
 }
 
-func walk(ctx context.Context, fs *flag.FlagSet, args []string) error {
-	var path string
-	fs.StringVar(&path, "path", "", "path to docs root")
+func (v varFlags) Set(raw string) error {
+	name, value, ok := strings.Cut(raw, "=")
+	if !ok || name == "" {
+		return xerrors.New("variables must be in the form name=value")
+	}
+	v[name] = value
+	return nil
+}
+
+func walk(ctx context.Context, fs *flag.FlagSet, lf *logFlags, args []string) error {
+	cf := &configFlags{}
+	cf.register(fs)
+	ef := &envFlags{}
+	ef.register(fs)
+	vars := make(varFlags)
+	fs.Var(vars, "var", "template variable as name=value, overrides config and environment (repeatable)")
+	var jsonReport, junitReport, markdownReport string
+	fs.StringVar(&jsonReport, "report-json", "", "write a JSON report of every operation to this file")
+	fs.StringVar(&junitReport, "report-junit", "", "write a JUnit XML report of every operation to this file")
+	fs.StringVar(&markdownReport, "report-markdown", "", "write a Markdown summary to this file, e.g. for a pull request comment")
+	var showDiff bool
+	fs.BoolVar(&showDiff, "diff", true, "log the changed fields and a unified diff of the body for every update")
+	var since string
+	fs.StringVar(&since, "since", "", "only compare files changed since this git ref, falling back to a full sync when the configuration changed")
+	lf.register(fs)
 
 	if err := fs.Parse(args); err != nil {
 		return xerrors.Errorf(": %w", err)
 	}
 
-	if path == "" {
-		return xerrors.New("empty path")
+	ctx, err := lf.apply(ctx)
+	if err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	log := lf.logger()
+
+	if err := ef.load(); err != nil {
+		return xerrors.Errorf(": %w", err)
 	}
 
-	cfg, err := config.NewConfig("")
+	cfg, err := loadSyncConfig(cf, ef)
 	if err != nil {
 		return xerrors.Errorf(": %w", err)
 	}
 
-	client, err := readme.NewClient(ctx, cfg.Key, cfg.Version)
+	statePath := specStatePath(cfg)
+	specState, err := docs.LoadSpecState(statePath)
 	if err != nil {
 		return xerrors.Errorf(": %w", err)
 	}
 
-	catalog, err := docs.WalkCatalog(ctx, path)
+	var changes *changeSet
+	if since != "" {
+		if changes, err = gitChanges(since); err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		if reason := changes.fullSyncReason(append([]string{cfg.File}, ef.paths()...)); reason != "" {
+			log.Info("Running a full sync", "reason", reason)
+			changes = nil
+		}
+	}
+
+	r := run{
+		vars:      vars,
+		specState: specState,
+		statePath: statePath,
+		report:    &report.Collector{},
+		diff:      showDiff,
+		color:     lf.color(),
+		since:     changes,
+	}
+
+	failed := r.syncAll(ctx, cfg)
+
+	// reports are written even when the sync failed, as that is when they are most useful
+	for _, out := range []struct {
+		path  string
+		write func(*report.Collector, io.Writer) error
+	}{
+		{jsonReport, (*report.Collector).WriteJSON},
+		{junitReport, (*report.Collector).WriteJUnit},
+		{markdownReport, (*report.Collector).WriteMarkdown},
+	} {
+		if err := r.report.WriteFile(out.path, out.write); err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+	}
+
+	if len(failed) > 0 {
+		return xerrors.New("sync failed for " + strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// loadSyncConfig loads the configuration and api keys for syncing, checking what the config package cannot
+func loadSyncConfig(cf *configFlags, ef *envFlags) (config.Config, error) {
+	cfg, err := cf.load(ef.keyReader())
+	if err != nil {
+		return config.Config{}, xerrors.Errorf(": %w", err)
+	}
+
+	for _, project := range cfg.SyncProjects() {
+		if err := docs.ValidateTransforms(project.Transforms); err != nil {
+			return config.Config{}, xerrors.Errorf(": %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// specStatePath keeps the state file next to the configuration file, so runs from other directories share it
+func specStatePath(cfg config.Config) string {
+	if cfg.File == "" {
+		return docs.SpecStateFile
+	}
+	return filepath.Join(filepath.Dir(cfg.File), docs.SpecStateFile)
+}
+
+// specStatePath is the path of a specification relative to the state file, so the state
+// matches the same specification whichever directory the sync runs from
+func (r *run) specStatePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	dir, err := filepath.Abs(filepath.Dir(r.statePath))
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// run holds the state shared by every version synced in one invocation
+type run struct {
+	cfg       config.Config // of the project being synced
+	project   string        // empty unless projects are configured
+	vars      varFlags
+	specState docs.SpecState
+	statePath string
+	report    *report.Collector
+	diff      bool       // log what changed on every update
+	since     *changeSet // limits syncing to changed files, nil for a full sync
+	unchanged int        // items skipped by since
+	color     bool
+}
+
+// syncAll syncs every version of every project, carrying on past failures, and returns what failed.
+// Blocks go before the versions that embed them, and the rest of the project-wide content after.
+func (r *run) syncAll(ctx context.Context, cfg config.Config) []string {
+	log := logging.FromContext(ctx)
+
+	var failed []string
+	for _, project := range cfg.SyncProjects() {
+		r.cfg, r.project = project.Config, project.Name
+		var projectAttrs []any
+		var prefix string
+		if project.Name != "" {
+			projectAttrs = []any{"project", project.Name}
+			prefix = project.Name + "/"
+		}
+
+		sharedResult := report.Result{Project: r.project, Kind: "shared", Slug: "changelog, custom pages and blocks"}
+		start := time.Now()
+		shared, client, err := r.syncBlocks(ctx)
+		if err != nil {
+			log.Error("Sync of project content failed", append(projectAttrs, "error", fmt.Sprintf("%+v", err))...)
+			r.fail(sharedResult, start, err)
+			failed = append(failed, prefix+"shared content")
+			continue
+		}
+
+		versionsOK := true
+		for _, target := range project.Targets() {
+			attrs := append(append([]any{}, projectAttrs...), "version", target.Version)
+			log.Info("Syncing version", attrs...)
+			start := time.Now()
+			if err := r.syncVersion(ctx, target, shared); err != nil {
+				log.Error("Sync of version failed", append(attrs, "error", fmt.Sprintf("%+v", err))...)
+				r.fail(report.Result{Project: r.project, Version: target.Version, Kind: "version", Slug: target.Version}, start, err)
+				failed = append(failed, prefix+target.Version)
+				versionsOK = false
+				continue
+			}
+			log.Info("Sync of version complete", attrs...)
+		}
+
+		start = time.Now()
+		if err := r.syncEntries(ctx, client, shared, versionsOK); err != nil {
+			log.Error("Sync of project content failed", append(projectAttrs, "error", fmt.Sprintf("%+v", err))...)
+			r.fail(sharedResult, start, err)
+			failed = append(failed, prefix+"shared content")
+		}
+
+		if r.since != nil {
+			log.Info("Skipped unchanged items", append(projectAttrs, "count", r.unchanged)...)
+			r.unchanged = 0
+		}
+	}
+	return failed
+}
+
+// fail records a failure that stopped a whole version or the project-wide content. Most of these,
+// such as a category missing from the configuration, happen outside any item and leave no result
+// of their own, so reports would otherwise show no failure.
+func (r *run) fail(base report.Result, start time.Time, err error) {
+	base.Action = report.Failed
+	base.Duration = time.Since(start)
+	base.Error = err.Error()
+	r.report.Record(base)
+}
+
+// docOptions are the options for processing items of the current project, with the variables of a version if any
+func (r *run) docOptions(versionVariables map[string]string) docs.DocOptions {
+	// flags beat the environment, which the config package already applied over top-level and version variables
+	variables := make(map[string]string)
+	for _, layer := range []map[string]string{r.cfg.Variables, versionVariables, r.vars} {
+		for name, value := range layer {
+			variables[name] = value
+		}
+	}
+
+	return docs.DocOptions{
+		Variables:   variables,
+		UnknownKeys: r.cfg.UnknownFrontMatter,
+		Transforms:  r.cfg.Transforms,
+		Diff:        r.diff,
+		Color:       r.color,
+	}
+}
+
+// changed reports whether an item built from paths, which are layered over roots, must be compared with readme,
+// counting the ones skipped by since
+func (r *run) changed(roots []string, paths ...string) bool {
+	if r.since == nil || r.since.any(paths...) || r.since.uncovered(roots, paths...) {
+		return true
+	}
+	r.unchanged++
+	return false
+}
+
+func (r *run) syncVersion(ctx context.Context, target config.VersionConfig, shared docs.Shared) error {
+	cfg, path := r.cfg, r.cfg.Path
+	log := logging.FromContext(ctx)
+	if target.Path != "" {
+		path = target.Path
+	}
+	if path == "" {
+		return xerrors.New("empty path")
+	}
+
+	client, err := readme.NewClient(ctx, cfg.Key, target.Version)
+	if err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+
+	// specifications go first as they generate the reference categories and pages
+	for _, spec := range target.Specs {
+		spec := spec
+		err := r.report.Track(report.Result{Project: r.project, Version: target.Version, Kind: "spec", Slug: spec.Path}, func() (report.Action, error) {
+			metadata := docs.SpecMetadata{Path: spec.Path, StatePath: r.specStatePath(spec.Path), Id: spec.Id, Project: r.project}
+			return docs.ProcessSpec(ctx, client, target.Version, metadata, r.specState)
+		})
+		if saveErr := r.specState.Save(r.statePath); saveErr != nil {
+			return xerrors.Errorf(": %w", saveErr)
+		}
+		if err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+	}
+
+	var reserved []string
+	for _, root := range []string{path, target.Overlay} {
+		if root == "" {
+			continue
+		}
+		names, err := reservedFolders(root, cfg)
+		if err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		reserved = append(reserved, names...)
+	}
+
+	catalog, err := docs.WalkCatalog(ctx, path, docs.WalkOptions{
+		Include:  cfg.Include,
+		Exclude:  cfg.Exclude,
+		Overlay:  target.Overlay,
+		Reserved: reserved,
+	})
 	if err != nil {
 		return xerrors.Errorf(": %w", err)
 	}
 
+	for _, skipped := range catalog.Skipped {
+		log.Info("Skipping file", "path", skipped.Path, "reason", skipped.Reason)
+	}
+
 	// Create the category config map
 	catConfigs := make(map[string]config.CategoryConfig)
-	for _, catCfg := range cfg.Categories {
+	catPositions := make(map[string]int)
+	for i, catCfg := range cfg.Categories {
 		catConfigs[catCfg.Slug] = catCfg
+		catPositions[catCfg.Slug] = i
 	}
+	lenient := cfg.MissingCategories == "slug"
 
-	// Make sure all categories in the catalog are represented in the config
-	for cat := range catalog.Categories {
-		if _, found := catConfigs[cat]; !found {
-			msg := fmt.Sprintf("Top-level folder with slug \"%v\" does not have a matching category entry in the configuration file", cat)
+	// Make sure all categories in the catalog are represented in the config or a category file
+	var unlisted []string
+	for cat, file := range catalog.Categories {
+		if _, found := catConfigs[cat]; found {
+			continue
+		}
+		if !file.FromFile && !lenient {
+			msg := fmt.Sprintf("Top-level folder with slug \"%v\" does not have a matching category entry in the configuration file or a _category.yml file", cat)
 			return xerrors.New(msg)
 		}
+		unlisted = append(unlisted, cat)
 	}
+	sort.Strings(unlisted) // categories not in the config list are ordered after it, alphabetically
 
 	// Make sure all categories in the config are represented in the catalog
 	for cat := range catConfigs {
 		if _, found := catalog.Categories[cat]; !found {
 			msg := fmt.Sprintf("Category configuration with slug \"%v\" does not have a matching top-level folder in the provided path", cat)
+			if lenient {
+				log.Warn(msg)
+				continue
+			}
 			return xerrors.New(msg)
 		}
 	}
+	for i, cat := range unlisted {
+		catPositions[cat] = len(cfg.Categories) + i
+	}
+
+	docOpts := r.docOptions(target.Variables)
 
-	for cat := range catalog.Categories {
+	var categories []docs.CatMetadata
+	for cat, file := range catalog.Categories {
 		metadata := docs.CatMetadata{
 			Slug:  cat,
 			Title: cat,
+			Type:  file.Type,
+			Order: catPositions[cat],
+		}
+		if file.Title != "" {
+			metadata.Title = file.Title
+		}
+		if file.Order != nil {
+			metadata.Order = *file.Order
 		}
 
 		catCfg, ok := catConfigs[cat]
 		if ok && catCfg.Title != "" { // readme does not accept empty titles
 			metadata.Title = catCfg.Title
 		}
+		if ok && catCfg.Type != "" {
+			metadata.Type = catCfg.Type
+		}
+		if ok && catCfg.Order != nil {
+			metadata.Order = *catCfg.Order
+		}
+		categories = append(categories, metadata)
+	}
 
-		if err := docs.ProcessCategory(ctx, client, metadata); err != nil {
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Slug < categories[j].Slug })
+	if err := docs.CheckNewCategories(ctx, client, categories); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	for _, metadata := range categories {
+		metadata := metadata
+		if err := r.report.Track(report.Result{Project: r.project, Version: target.Version, Kind: "category", Slug: metadata.Slug}, func() (report.Action, error) {
+			return docs.ProcessCategory(ctx, client, metadata, docOpts)
+		}); err != nil {
 			return xerrors.Errorf(": %w", err)
 		}
 	}
 
+	// Make sure every embedded block exists
+	if catalog.ReferencesBlocks() {
+		known, err := knownBlocks(ctx, client, shared)
+		if err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		if err := catalog.ValidateBlockReferences(known); err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+	}
+
+	// with -since only items whose files changed are compared with readme
+	processDoc := func(doc docs.DocMetadata) error {
+		if !r.changed(doc.Roots, append([]string{doc.Filepath}, doc.Includes...)...) {
+			return nil
+		}
+		return r.report.Track(report.Result{Project: r.project, Version: target.Version, Kind: "doc", Slug: doc.Slug, Category: doc.Category}, func() (report.Action, error) {
+			return docs.ProcessDoc(ctx, client, doc, docOpts)
+		})
+	}
+
 	for _, doc := range catalog.Docs {
 		if doc.Parent == "" {
-			if err := docs.ProcessDoc(ctx, client, doc); err != nil {
+			if err := processDoc(doc); err != nil {
 				return xerrors.Errorf(": %w", err)
 			}
 		}
@@ -106,20 +466,28 @@ func walk(ctx context.Context, fs *flag.FlagSet, args []string) error {
 
 	for _, doc := range catalog.Docs {
 		if doc.Parent != "" {
-			if err := docs.ProcessDoc(ctx, client, doc); err != nil {
+			if err := processDoc(doc); err != nil {
 				return xerrors.Errorf(": %w", err)
 			}
 		}
 	}
 
-	if err := prune(context.Background(), client, catalog); err != nil {
-		return xerrors.Errorf(": %w", err)
+	// nothing can have disappeared locally when no file was deleted or renamed
+	if r.since == nil || r.since.removed {
+		if err := r.prune(ctx, client, target.Version, catalog, len(target.Specs) > 0); err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
 	}
 
 	return nil
 }
 
-func prune(ctx context.Context, client *readme.Client, catalog docs.Catalog) error {
+// keepReference leaves reference categories missing from the catalog alone, as uploaded specifications generate them
+func (r *run) prune(ctx context.Context, client *readme.Client, version string, catalog docs.Catalog, keepReference bool) error {
+	del := func(kind, slug, category string, fn func(context.Context, string) error) error {
+		return r.del(ctx, report.Result{Project: r.project, Version: version, Kind: kind, Slug: slug, Category: category}, fn)
+	}
+
 	cats, err := client.GetCategories(ctx)
 	if err != nil {
 		return xerrors.Errorf(": %w", err)
@@ -128,8 +496,10 @@ func prune(ctx context.Context, client *readme.Client, catalog docs.Catalog) err
 	for _, cat := range cats {
 		// Deleting a category automatically removes all contained docs, saving time on the next step
 		if _, found := catalog.Categories[cat.Slug]; !found {
-			fmt.Printf("Pruning category with slug \"%v\"\n", cat.Slug)
-			if err := client.DeleteCategory(ctx, cat.Slug); err != nil {
+			if keepReference && cat.Type == "reference" {
+				continue
+			}
+			if err := del("category", cat.Slug, "", client.DeleteCategory); err != nil {
 				return xerrors.Errorf(": %w", err)
 			}
 			continue
@@ -142,8 +512,7 @@ func prune(ctx context.Context, client *readme.Client, catalog docs.Catalog) err
 
 		pruneDoc := func(doc readme.Document) error {
 			if _, found := catalog.Docs[doc.Slug]; !found {
-				fmt.Printf("Pruning doc with slug \"%v\"\n", doc.Slug)
-				if err := client.DeleteDoc(ctx, doc.Slug); err != nil {
+				if err := del("doc", doc.Slug, doc.Category, client.DeleteDoc); err != nil {
 					return xerrors.Errorf(": %w", err)
 				}
 			}
@@ -169,3 +538,11 @@ func prune(ctx context.Context, client *readme.Client, catalog docs.Catalog) err
 
 	return nil
 }
+
+// del deletes a single item, recording the deletion on top of base
+func (r *run) del(ctx context.Context, base report.Result, fn func(context.Context, string) error) error {
+	logging.FromContext(ctx).Info("Pruning "+strings.ReplaceAll(base.Kind, "-", " "), "slug", base.Slug)
+	return r.report.Track(base, func() (report.Action, error) {
+		return report.Deleted, fn(ctx, base.Slug)
+	})
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rolflewis/readme-sync/config"
+)
+
+func TestVariableLayers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	contents := `
+path: docs
+variables:
+  top: top
+  version: top
+  env: top
+  flag: top
+versions:
+  - version: v1
+    variables:
+      version: version
+      env: version
+      flag: version
+`
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("README_VAR_env", "env")
+	t.Setenv("README_VAR_flag", "env")
+
+	cfg, err := config.Load(path, config.Overrides{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	r := &run{cfg: cfg, vars: varFlags{"flag": "flag"}}
+	got := r.docOptions(cfg.Versions[0].Variables).Variables
+
+	want := map[string]string{"top": "top", "version": "version", "env": "env", "flag": "flag"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("variables (-want +got):\n%v", diff)
+	}
+}
@@ -0,0 +1,75 @@
+package readme
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/xerrors"
+)
+
+// Block is a piece of reusable content, embedded in docs by its tag
+type Block struct {
+	Id   string `json:"_id,omitempty"`
+	Tag  string `json:"tag"`
+	Name string `json:"name"`
+	Type string `json:"type"` // markdown or html
+	Body string `json:"body"`
+}
+
+func (c *Client) GetBlocks(ctx context.Context) ([]Block, error) {
+	blocks, err := doAllPages[Block](c, http.MethodGet, "/api/v1/customblocks")
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+	return blocks, nil
+}
+
+func (c *Client) GetBlock(ctx context.Context, tag string) (Block, error) {
+	block, err := do[Block](c, doOpts{
+		method:         http.MethodGet,
+		path:           fmt.Sprintf("/api/v1/customblocks/%v", tag),
+		expectedStatus: http.StatusOK,
+	})
+	if err != nil {
+		return Block{}, xerrors.Errorf(": %w", err)
+	}
+	return block, nil
+}
+
+func (c *Client) CreateBlock(ctx context.Context, block Block) error {
+	block.Id = ""
+	if _, err := do[Block](c, doOpts{
+		method:         http.MethodPost,
+		path:           "/api/v1/customblocks",
+		expectedStatus: http.StatusCreated,
+		body:           block,
+	}); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+func (c *Client) UpdateBlock(ctx context.Context, block Block) error {
+	block.Id = ""
+	if _, err := do[Block](c, doOpts{
+		method:         http.MethodPut,
+		path:           fmt.Sprintf("/api/v1/customblocks/%v", block.Tag),
+		expectedStatus: http.StatusOK,
+		body:           block,
+	}); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+func (c *Client) DeleteBlock(ctx context.Context, tag string) error {
+	if _, err := do[Block](c, doOpts{
+		method:         http.MethodDelete,
+		path:           fmt.Sprintf("/api/v1/customblocks/%v", tag),
+		expectedStatus: http.StatusNoContent,
+	}); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
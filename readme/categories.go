@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/gosimple/slug"
 	"golang.org/x/xerrors"
 )
 
@@ -12,6 +14,8 @@ type Category struct {
 	Id    string `json:"_id,omitempty"`
 	Slug  string `json:"slug,omitempty"`
 	Title string `json:"title,omitempty"`
+	Type  string `json:"type,omitempty"` // guide or reference
+	Order int    `json:"order"`
 }
 
 // TODO: add auto paging
@@ -37,38 +41,94 @@ func (c *Client) GetCategory(ctx context.Context, slug string) (Category, error)
 	return cat, nil
 }
 
-// Creates a category with the given name and a slug equivalent to the slugified name
-// First creates the category with the slug title, then updates it to the original
-// title if the title is not equal to the slug (change in case, spacing, etc)
-// This is necessary due to lack of slug field on category creation.
-func (c *Client) CreateCategory(ctx context.Context, cat Category) error {
-	// First, create the category
+// SlugMismatchError is returned when readme derives a different slug for a new category, changelog or custom page than the one requested
+type SlugMismatchError struct {
+	Want string
+	Got  string
+}
+
+func (e *SlugMismatchError) Error() string {
+	return fmt.Sprintf("readme assigned slug \"%v\" instead of \"%v\" - the slug may collide with an existing or reserved slug", e.Got, e.Want)
+}
+
+// createTitle is the title a category is created with. The api has no slug field on creation and
+// derives it from the title instead, so the category is created directly with its title when that
+// title produces the wanted slug, and otherwise titled with the slug and renamed afterwards.
+func createTitle(cat Category) string {
+	if slug.Make(cat.Title) == cat.Slug {
+		return cat.Title
+	}
+	return cat.Slug
+}
+
+// CheckNewCategories fails when a category in created would not get its slug from readme: a
+// *SlugMismatchError when the slug is not in slug form, otherwise an error when another category,
+// in existing or earlier in created, already takes the slug in any case. Run it before creating
+// anything so that a collision leaves no partial changes behind.
+func CheckNewCategories(existing, created []Category) error {
+	taken := make(map[string]string)
+	for _, cat := range existing {
+		taken[strings.ToLower(cat.Slug)] = cat.Slug
+	}
+	for _, cat := range created {
+		derived := slug.Make(createTitle(cat))
+		if derived != cat.Slug {
+			return xerrors.Errorf(": %w", &SlugMismatchError{Want: cat.Slug, Got: derived})
+		}
+		if other, found := taken[strings.ToLower(derived)]; found {
+			return xerrors.New(fmt.Sprintf("category slug \"%v\" collides with existing category \"%v\"", cat.Slug, other))
+		}
+		taken[strings.ToLower(derived)] = cat.Slug
+	}
+	return nil
+}
+
+// Creates a category and returns it as stored by readme, see createTitle. If readme assigns a
+// different slug than wanted, e.g. because the slug is reserved, the new category is removed
+// again and a *SlugMismatchError is returned.
+func (c *Client) CreateCategory(ctx context.Context, cat Category) (Category, error) {
 	createPayload := Category{
-		Title: cat.Slug,
+		Title: createTitle(cat),
+		Type:  cat.Type,
+		Order: cat.Order,
 	}
+	rename := createPayload.Title != cat.Title
 
-	if _, err := do[Category](c, doOpts{
+	created, err := do[Category](c, doOpts{
 		method:         http.MethodPost,
 		path:           "/api/v1/categories",
 		expectedStatus: http.StatusCreated,
 		body:           createPayload,
-	}); err != nil {
-		return xerrors.Errorf(": %w", err)
+	})
+	if err != nil {
+		return Category{}, xerrors.Errorf(": %w", err)
+	}
+
+	if created.Slug == "" {
+		return Category{}, xerrors.Errorf("readme returned no slug for new category \"%v\"", cat.Slug)
+	}
+	if created.Slug != cat.Slug {
+		if err := c.DeleteCategory(ctx, created.Slug); err != nil {
+			return Category{}, xerrors.Errorf("removing category with unexpected slug \"%v\": %w", created.Slug, err)
+		}
+		return Category{}, xerrors.Errorf(": %w", &SlugMismatchError{Want: cat.Slug, Got: created.Slug})
 	}
 
-	// Second, update it if the slug != title
-	if cat.Slug != cat.Title {
+	if rename {
 		if err := c.UpdateCategory(ctx, cat); err != nil {
-			return xerrors.Errorf(": %w", err)
+			return Category{}, xerrors.Errorf(": %w", err)
 		}
+		created.Title = cat.Title
 	}
 
-	return nil
+	return created, nil
 }
 
 func (c *Client) UpdateCategory(ctx context.Context, cat Category) error {
 	updatePayload := Category{
 		Title: cat.Title,
+		Type:  cat.Type,
+		Order: cat.Order,
 	}
 
 	if _, err := do[Category](c, doOpts{
@@ -0,0 +1,45 @@
+package readme
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckNewCategories(t *testing.T) {
+	existing := []Category{
+		{Slug: "guides", Title: "Guides"},
+		{Slug: "Legacy", Title: "Legacy"},
+	}
+	tests := []struct {
+		name     string
+		created  []Category
+		mismatch bool // a *SlugMismatchError is expected
+		wantErr  bool
+	}{
+		{name: "nothing to create"},
+		{name: "title produces the slug", created: []Category{{Slug: "getting-started", Title: "Getting Started"}}},
+		{name: "created under the slug and renamed", created: []Category{{Slug: "intro", Title: "Welcome!"}}},
+		{name: "slug not in slug form", created: []Category{{Slug: "Intro", Title: "Intro"}}, wantErr: true, mismatch: true},
+		{name: "slug taken in another case", created: []Category{{Slug: "legacy", Title: "Legacy"}}, wantErr: true},
+		{name: "slug taken by another new category", created: []Category{{Slug: "api", Title: "API"}, {Slug: "api", Title: "Api Reference"}}, wantErr: true},
+		{name: "several new categories", created: []Category{{Slug: "api", Title: "API"}, {Slug: "sdks", Title: "SDKs"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckNewCategories(existing, tt.created)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("CheckNewCategories: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			var mismatch *SlugMismatchError
+			if errors.As(err, &mismatch) != tt.mismatch {
+				t.Errorf("error %v: slug mismatch = %v, want %v", err, !tt.mismatch, tt.mismatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package readme
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/xerrors"
+)
+
+// Changelog types supported by readme, an empty type is also allowed
+var ChangelogTypes = []string{"added", "fixed", "improved", "deprecated", "removed"}
+
+type Changelog struct {
+	Id        string `json:"_id,omitempty"`
+	Slug      string `json:"slug,omitempty"`
+	Title     string `json:"title"`
+	Type      string `json:"type"`
+	Body      string `json:"body"`
+	Hidden    bool   `json:"hidden"`
+	CreatedAt string `json:"createdAt,omitempty"` // RFC 3339, used as the publish date
+}
+
+func (c *Client) GetChangelogs(ctx context.Context) ([]Changelog, error) {
+	changelogs, err := doAllPages[Changelog](c, http.MethodGet, "/api/v1/changelogs")
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+	return changelogs, nil
+}
+
+func (c *Client) GetChangelog(ctx context.Context, slug string) (Changelog, error) {
+	changelog, err := do[Changelog](c, doOpts{
+		method:         http.MethodGet,
+		path:           fmt.Sprintf("/api/v1/changelogs/%v", slug),
+		expectedStatus: http.StatusOK,
+	})
+	if err != nil {
+		return Changelog{}, xerrors.Errorf(": %w", err)
+	}
+	return changelog, nil
+}
+
+// Creates a changelog entry. Like categories, readme derives the slug from the title,
+// so a *SlugMismatchError is returned (after removing the entry) if it is not the expected one.
+func (c *Client) CreateChangelog(ctx context.Context, changelog Changelog) (Changelog, error) {
+	payload := changelog
+	payload.Id = ""
+	payload.Slug = ""
+
+	created, err := do[Changelog](c, doOpts{
+		method:         http.MethodPost,
+		path:           "/api/v1/changelogs",
+		expectedStatus: http.StatusCreated,
+		body:           payload,
+	})
+	if err != nil {
+		return Changelog{}, xerrors.Errorf(": %w", err)
+	}
+
+	if created.Slug != changelog.Slug {
+		if err := c.DeleteChangelog(ctx, created.Slug); err != nil {
+			return Changelog{}, xerrors.Errorf("removing changelog with unexpected slug \"%v\": %w", created.Slug, err)
+		}
+		return Changelog{}, xerrors.Errorf(": %w", &SlugMismatchError{Want: changelog.Slug, Got: created.Slug})
+	}
+	return created, nil
+}
+
+func (c *Client) UpdateChangelog(ctx context.Context, changelog Changelog) error {
+	payload := changelog
+	payload.Id = ""
+	payload.Slug = ""
+
+	if _, err := do[Changelog](c, doOpts{
+		method:         http.MethodPut,
+		path:           fmt.Sprintf("/api/v1/changelogs/%v", changelog.Slug),
+		expectedStatus: http.StatusOK,
+		body:           payload,
+	}); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+func (c *Client) DeleteChangelog(ctx context.Context, slug string) error {
+	if _, err := do[Changelog](c, doOpts{
+		method:         http.MethodDelete,
+		path:           fmt.Sprintf("/api/v1/changelogs/%v", slug),
+		expectedStatus: http.StatusNoContent,
+	}); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
@@ -4,11 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/rolflewis/readme-sync/logging"
 	"golang.org/x/xerrors"
 )
 
@@ -16,6 +22,7 @@ type Client struct {
 	apiKey  string
 	version string
 	url     string
+	log     *logging.Logger
 }
 
 func NewClient(ctx context.Context, apiKey string, version string) (*Client, error) {
@@ -27,6 +34,7 @@ func NewClient(ctx context.Context, apiKey string, version string) (*Client, err
 		apiKey:  apiKey,
 		version: version,
 		url:     "https://dash.readme.com",
+		log:     logging.FromContext(ctx),
 	}
 
 	return &c, nil
@@ -37,16 +45,41 @@ type doOpts struct {
 	path           string
 	expectedStatus int
 	body           any
+	upload         *upload // sent as multipart form data instead of body
+}
+
+// a file sent as a single multipart form field
+type upload struct {
+	field    string
+	filename string
+	data     []byte
 }
 
 func do[T any](c *Client, opts doOpts) (out T, err error) {
 	var payload io.Reader
+	var contentType string
 	if opts.body != nil {
 		buffer := new(bytes.Buffer)
 		if err := json.NewEncoder(buffer).Encode(opts.body); err != nil {
 			return out, xerrors.Errorf(": %w", err)
 		}
 		payload = buffer
+		contentType = "application/json"
+	} else if opts.upload != nil {
+		buffer := new(bytes.Buffer)
+		form := multipart.NewWriter(buffer)
+		part, err := form.CreateFormFile(opts.upload.field, opts.upload.filename)
+		if err != nil {
+			return out, xerrors.Errorf(": %w", err)
+		}
+		if _, err := part.Write(opts.upload.data); err != nil {
+			return out, xerrors.Errorf(": %w", err)
+		}
+		if err := form.Close(); err != nil {
+			return out, xerrors.Errorf(": %w", err)
+		}
+		payload = buffer
+		contentType = form.FormDataContentType()
 	}
 
 	url := c.url + opts.path // unified host url
@@ -56,28 +89,44 @@ func do[T any](c *Client, opts doOpts) (out T, err error) {
 	}
 
 	if payload != nil {
-		req.Header.Add("content-type", "application/json")
+		req.Header.Add("content-type", contentType)
 	}
 
 	req.Header.Add("accept", "application/json")
 	req.Header.Add("x-readme-version", c.version)
 	req.SetBasicAuth(c.apiKey, "")
 
+	trace := c.log.Enabled(logging.LevelDebug)
+	if trace {
+		c.log.Debug("http request", "method", req.Method, "url", url, "headers", redactedHeaders(req.Header), "body", traceBody(payload))
+	}
+
+	start := time.Now()
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return out, xerrors.Errorf(": %w", err)
 	}
 	defer res.Body.Close()
 
+	var body io.Reader = res.Body
+	if trace {
+		contents, err := io.ReadAll(res.Body)
+		if err != nil {
+			return out, xerrors.Errorf(": %w", err)
+		}
+		c.log.Debug("http response", "method", req.Method, "url", url, "status", res.StatusCode, "duration", time.Since(start), "body", truncate(string(contents)))
+		body = bytes.NewReader(contents)
+	}
+
 	if res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusNoContent {
 		return out, nil
 	}
 
 	if res.StatusCode != opts.expectedStatus {
-		return out, xerrors.Errorf(": %w", handleErrorResponse(res.Body))
+		return out, xerrors.Errorf(": %w", handleErrorResponse(body))
 	}
 
-	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+	if err := json.NewDecoder(body).Decode(&out); err != nil {
 		return out, xerrors.Errorf(": %w", err)
 	}
 
@@ -120,3 +169,37 @@ func doAllPages[T any](c *Client, method, path string) ([]T, error) {
 	}
 	return results, nil
 }
+
+// the api key is sent as basic auth, so the credentials header never reaches the log
+func redactedHeaders(header http.Header) string {
+	var pairs []string
+	for name, values := range header {
+		value := strings.Join(values, ",")
+		if strings.EqualFold(name, "authorization") {
+			value = "REDACTED"
+		}
+		pairs = append(pairs, name+": "+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "; ")
+}
+
+// multipart uploads are summarised by size, json payloads are logged as sent
+func traceBody(payload io.Reader) string {
+	buffer, ok := payload.(*bytes.Buffer)
+	if !ok {
+		return ""
+	}
+	if !json.Valid(buffer.Bytes()) {
+		return fmt.Sprintf("<%d bytes>", buffer.Len())
+	}
+	return truncate(strings.TrimSpace(buffer.String()))
+}
+
+func truncate(s string) string {
+	const limit = 2048
+	if len(s) > limit {
+		return s[:limit] + fmt.Sprintf("... <%d bytes>", len(s))
+	}
+	return s
+}
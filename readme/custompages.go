@@ -0,0 +1,92 @@
+package readme
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/xerrors"
+)
+
+type CustomPage struct {
+	Id       string `json:"_id,omitempty"`
+	Slug     string `json:"slug,omitempty"`
+	Title    string `json:"title"`
+	Body     string `json:"body"` // markdown content, used when HtmlMode is false
+	Html     string `json:"html"` // html content, used when HtmlMode is true
+	HtmlMode bool   `json:"htmlmode"`
+	Hidden   bool   `json:"hidden"`
+}
+
+func (c *Client) GetCustomPages(ctx context.Context) ([]CustomPage, error) {
+	pages, err := doAllPages[CustomPage](c, http.MethodGet, "/api/v1/custompages")
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+	return pages, nil
+}
+
+func (c *Client) GetCustomPage(ctx context.Context, slug string) (CustomPage, error) {
+	page, err := do[CustomPage](c, doOpts{
+		method:         http.MethodGet,
+		path:           fmt.Sprintf("/api/v1/custompages/%v", slug),
+		expectedStatus: http.StatusOK,
+	})
+	if err != nil {
+		return CustomPage{}, xerrors.Errorf(": %w", err)
+	}
+	return page, nil
+}
+
+// Creates a custom page. Readme derives the slug from the title, so a *SlugMismatchError
+// is returned (after removing the page) if it is not the expected one.
+func (c *Client) CreateCustomPage(ctx context.Context, page CustomPage) (CustomPage, error) {
+	payload := page
+	payload.Id = ""
+	payload.Slug = ""
+
+	created, err := do[CustomPage](c, doOpts{
+		method:         http.MethodPost,
+		path:           "/api/v1/custompages",
+		expectedStatus: http.StatusCreated,
+		body:           payload,
+	})
+	if err != nil {
+		return CustomPage{}, xerrors.Errorf(": %w", err)
+	}
+
+	if created.Slug != page.Slug {
+		if err := c.DeleteCustomPage(ctx, created.Slug); err != nil {
+			return CustomPage{}, xerrors.Errorf("removing custom page with unexpected slug \"%v\": %w", created.Slug, err)
+		}
+		return CustomPage{}, xerrors.Errorf(": %w", &SlugMismatchError{Want: page.Slug, Got: created.Slug})
+	}
+	return created, nil
+}
+
+func (c *Client) UpdateCustomPage(ctx context.Context, page CustomPage) error {
+	payload := page
+	payload.Id = ""
+	payload.Slug = ""
+
+	if _, err := do[CustomPage](c, doOpts{
+		method:         http.MethodPut,
+		path:           fmt.Sprintf("/api/v1/custompages/%v", page.Slug),
+		expectedStatus: http.StatusOK,
+		body:           payload,
+	}); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+func (c *Client) DeleteCustomPage(ctx context.Context, slug string) error {
+	if _, err := do[CustomPage](c, doOpts{
+		method:         http.MethodDelete,
+		path:           fmt.Sprintf("/api/v1/custompages/%v", slug),
+		expectedStatus: http.StatusNoContent,
+	}); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
@@ -15,16 +15,88 @@ type apiErrorResponse struct {
 	Message string `json:"message"`
 }
 
+// Document types supported by readme
+const (
+	DocTypeBasic = "basic"
+	DocTypeLink  = "link"
+	DocTypeError = "error"
+)
+
 type Document struct {
-	Id       string `json:"_id,omitempty"`
-	Slug     string `json:"slug"`
-	Title    string `json:"title"`
-	Excerpt  string `json:"excerpt"`
-	Body     string `json:"body"`
-	Category string `json:"categorySlug"`
-	Parent   string `json:"parentDocSlug"`
-	Hidden   bool   `json:"hidden"`
-	Order    int    `json:"order"`
+	Id           string       `json:"_id,omitempty"`
+	Slug         string       `json:"slug"`
+	Title        string       `json:"title"`
+	Type         string       `json:"type"`
+	Excerpt      string       `json:"excerpt"`
+	Body         string       `json:"body"`
+	Category     string       `json:"categorySlug"`
+	Parent       string       `json:"parentDocSlug"`
+	Hidden       bool         `json:"hidden"`
+	Order        int          `json:"order"`
+	LinkUrl      string       `json:"link_url"`
+	LinkExternal bool         `json:"link_external"`
+	Metadata     DocSeo       `json:"metadata"`
+	Next         NextSteps    `json:"next"`
+	Error        ErrorDetails `json:"error"`
+}
+
+// SEO metadata shown in page headers and link previews
+type DocSeo struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Image       ImageRef `json:"image"`
+}
+
+// ImageRef is the url of an uploaded image.
+// readme represents images as an array of url, filename, dimensions and color,
+// but only the url can be set through the api, so it is all that is tracked here.
+type ImageRef string
+
+func (i ImageRef) MarshalJSON() ([]byte, error) {
+	if i == "" {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string{string(i)})
+}
+
+// UnmarshalJSON takes the url from an array, an object with a url or a plain string.
+// Any other shape is read as no image rather than failing the whole doc.
+func (i *ImageRef) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	*i = ""
+	switch value := value.(type) {
+	case []any:
+		if len(value) > 0 {
+			if url, ok := value[0].(string); ok {
+				*i = ImageRef(url)
+			}
+		}
+	case map[string]any:
+		if url, ok := value["url"].(string); ok {
+			*i = ImageRef(url)
+		}
+	case string:
+		*i = ImageRef(value)
+	}
+	return nil
+}
+
+type NextSteps struct {
+	Description string     `json:"description"`
+	Pages       []NextPage `json:"pages"`
+}
+
+type NextPage struct {
+	Type string `json:"type"` // doc or ref
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+type ErrorDetails struct {
+	Code string `json:"code"`
 }
 
 func (c *Client) PutDoc(ctx context.Context, doc Document) error {
@@ -32,6 +104,7 @@ func (c *Client) PutDoc(ctx context.Context, doc Document) error {
 		method:         http.MethodPut,
 		path:           fmt.Sprintf("/api/v1/docs/%v", doc.Slug),
 		expectedStatus: http.StatusOK,
+		body:           doc,
 	}); err != nil {
 		return xerrors.Errorf(": %w", err)
 	}
@@ -63,7 +136,7 @@ func (c *Client) GetDoc(ctx context.Context, slug string) (Document, error) {
 		return Document{}, xerrors.Errorf(": %w", err)
 	}
 
-	if resp.Document == (Document{}) {
+	if resp.Id == "" {
 		return Document{}, nil
 	}
 
@@ -0,0 +1,52 @@
+package readme
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+)
+
+type Spec struct {
+	Id    string `json:"_id"`
+	Title string `json:"title"`
+}
+
+func (c *Client) GetSpecs(ctx context.Context) ([]Spec, error) {
+	specs, err := doAllPages[Spec](c, http.MethodGet, "/api/v1/api-specification")
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+	return specs, nil
+}
+
+// Uploads a new api specification, returning the id readme assigned to it
+func (c *Client) CreateSpec(ctx context.Context, filename string, data []byte) (string, error) {
+	spec, err := do[Spec](c, doOpts{
+		method:         http.MethodPost,
+		path:           "/api/v1/api-specification",
+		expectedStatus: http.StatusCreated,
+		upload:         &upload{field: "spec", filename: filepath.Base(filename), data: data},
+	})
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+	if spec.Id == "" {
+		return "", xerrors.New("readme did not return an id for the new specification")
+	}
+	return spec.Id, nil
+}
+
+func (c *Client) UpdateSpec(ctx context.Context, id, filename string, data []byte) error {
+	if _, err := do[Spec](c, doOpts{
+		method:         http.MethodPut,
+		path:           fmt.Sprintf("/api/v1/api-specification/%v", id),
+		expectedStatus: http.StatusOK,
+		upload:         &upload{field: "spec", filename: filepath.Base(filename), data: data},
+	}); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package readme
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/xerrors"
+)
+
+type Version struct {
+	Id           string `json:"_id,omitempty"`
+	Version      string `json:"version"`
+	Codename     string `json:"codename"`
+	From         string `json:"from,omitempty"` // version to fork from, only used on creation
+	IsStable     bool   `json:"is_stable"`
+	IsBeta       bool   `json:"is_beta"`
+	IsHidden     bool   `json:"is_hidden"`
+	IsDeprecated bool   `json:"is_deprecated"`
+}
+
+// endpoint does not support paging at time of writing
+func (c *Client) GetVersions(ctx context.Context) ([]Version, error) {
+	versions, err := do[[]Version](c, doOpts{
+		method:         http.MethodGet,
+		path:           "/api/v1/version",
+		expectedStatus: http.StatusOK,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+	return versions, nil
+}
+
+func (c *Client) GetVersion(ctx context.Context, version string) (Version, error) {
+	v, err := do[Version](c, doOpts{
+		method:         http.MethodGet,
+		path:           fmt.Sprintf("/api/v1/version/%v", url.PathEscape(version)),
+		expectedStatus: http.StatusOK,
+	})
+	if err != nil {
+		return Version{}, xerrors.Errorf(": %w", err)
+	}
+	return v, nil
+}
+
+// Creates a new version by forking the docs of v.From
+func (c *Client) CreateVersion(ctx context.Context, v Version) error {
+	if v.From == "" {
+		return xerrors.New("a version to fork from is required")
+	}
+
+	if _, err := do[Version](c, doOpts{
+		method:         http.MethodPost,
+		path:           "/api/v1/version",
+		expectedStatus: http.StatusOK,
+		body:           v,
+	}); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+// Updates the version named by current, which may be renamed through v.Version
+func (c *Client) UpdateVersion(ctx context.Context, current string, v Version) error {
+	v.Id = ""
+	v.From = ""
+
+	if _, err := do[Version](c, doOpts{
+		method:         http.MethodPut,
+		path:           fmt.Sprintf("/api/v1/version/%v", url.PathEscape(current)),
+		expectedStatus: http.StatusOK,
+		body:           v,
+	}); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+// Deletes the version, a missing version is an error as the API answers it with a 404
+func (c *Client) DeleteVersion(ctx context.Context, version string) error {
+	existing, err := c.GetVersion(ctx, version)
+	if err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	if existing.Version == "" {
+		return xerrors.New(fmt.Sprintf("version \"%v\" not found", version))
+	}
+
+	if _, err := do[Version](c, doOpts{
+		method:         http.MethodDelete,
+		path:           fmt.Sprintf("/api/v1/version/%v", url.PathEscape(version)),
+		expectedStatus: http.StatusOK,
+	}); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
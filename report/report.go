@@ -0,0 +1,246 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Action is what a sync did to a single item
+type Action string
+
+const (
+	Created   Action = "created"
+	Updated   Action = "updated"
+	Unchanged Action = "unchanged"
+	Deleted   Action = "deleted"
+	Failed    Action = "failed"
+)
+
+// Result records one operation of a sync
+type Result struct {
+	Project  string        `json:"project,omitempty"`
+	Version  string        `json:"version"` // empty for changelogs, custom pages and blocks, which readme keeps per project
+	Kind     string        `json:"kind"`    // category, doc, changelog, custom-page, block or spec, or version or shared when a whole step failed
+	Slug     string        `json:"slug"`
+	Category string        `json:"category,omitempty"`
+	Action   Action        `json:"action"`
+	Duration time.Duration `json:"duration_ns"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// scope names the project and version a result belongs to
+func (r Result) scope() string {
+	version := r.Version
+	if version == "" {
+		version = "all versions"
+	}
+	if r.Project != "" {
+		return r.Project + "/" + version
+	}
+	return version
+}
+
+// Collector gathers results over a whole run, across versions
+type Collector struct {
+	mu      sync.Mutex
+	results []Result
+}
+
+func (c *Collector) Record(r Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = append(c.results, r)
+}
+
+func (c *Collector) Results() []Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Result{}, c.results...)
+}
+
+// Track runs fn, timing it and recording its action or error on top of base
+func (c *Collector) Track(base Result, fn func() (Action, error)) error {
+	start := time.Now()
+	action, err := fn()
+	base.Duration = time.Since(start)
+	base.Action = action
+	if err != nil {
+		base.Action = Failed
+		base.Error = err.Error()
+	}
+	c.Record(base)
+	return err
+}
+
+func (c *Collector) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(struct {
+		Results []Result `json:"results"`
+	}{c.Results()}); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes one test suite per project and version, with one test case per operation
+func (c *Collector) WriteJUnit(w io.Writer) error {
+	var suites junitSuites
+	index := make(map[string]int)
+	for _, r := range c.Results() {
+		i, found := index[r.scope()]
+		if !found {
+			i = len(suites.Suites)
+			index[r.scope()] = i
+			suites.Suites = append(suites.Suites, junitSuite{Name: "readme-sync " + r.scope()})
+		}
+		suite := &suites.Suites[i]
+
+		tc := junitCase{
+			Name:      fmt.Sprintf("%v %v", r.Kind, r.Slug),
+			Classname: r.Kind,
+			Time:      seconds(r.Duration),
+			SystemOut: string(r.Action),
+		}
+		if r.Action == Failed {
+			tc.Failure = &junitFailure{Message: firstLine(r.Error), Text: r.Error}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+	for i := range suites.Suites {
+		var total time.Duration
+		for _, r := range c.Results() {
+			if "readme-sync "+r.scope() == suites.Suites[i].Name {
+				total += r.Duration
+			}
+		}
+		suites.Suites[i].Time = seconds(total)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+// WriteMarkdown writes a summary suitable for a pull request comment
+func (c *Collector) WriteMarkdown(w io.Writer) error {
+	results := c.Results()
+	counts := make(map[Action]int)
+	for _, r := range results {
+		counts[r.Action]++
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## ReadMe sync\n\n")
+	sb.WriteString("| Created | Updated | Deleted | Unchanged | Failed |\n")
+	sb.WriteString("| ---: | ---: | ---: | ---: | ---: |\n")
+	fmt.Fprintf(&sb, "| %v | %v | %v | %v | %v |\n", counts[Created], counts[Updated], counts[Deleted], counts[Unchanged], counts[Failed])
+
+	var changed []Result
+	for _, r := range results {
+		if r.Action != Unchanged {
+			changed = append(changed, r)
+		}
+	}
+	if len(changed) > 0 {
+		sort.SliceStable(changed, func(i, j int) bool { return changed[i].scope() < changed[j].scope() })
+		scope := "Version"
+		for _, r := range changed {
+			if r.Project != "" {
+				scope = "Project/Version"
+			}
+		}
+		fmt.Fprintf(&sb, "\n| %v | Kind | Slug | Category | Action |\n", scope)
+		sb.WriteString("| --- | --- | --- | --- | --- |\n")
+		for _, r := range changed {
+			action := string(r.Action)
+			if r.Error != "" {
+				action += ": " + escapeCell(firstLine(r.Error))
+			}
+			fmt.Fprintf(&sb, "| %v | %v | `%v` | %v | %v |\n", r.scope(), r.Kind, r.Slug, r.Category, action)
+		}
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+// WriteFile writes the report to path using one of the Write methods, doing nothing when path is empty
+func (c *Collector) WriteFile(path string, write func(*Collector, io.Writer) error) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	if err := write(c, f); err != nil {
+		f.Close()
+		return xerrors.Errorf(": %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	return nil
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
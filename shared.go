@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rolflewis/readme-sync/config"
+	"github.com/rolflewis/readme-sync/docs"
+	"github.com/rolflewis/readme-sync/logging"
+	"github.com/rolflewis/readme-sync/readme"
+	"github.com/rolflewis/readme-sync/report"
+	"golang.org/x/xerrors"
+)
+
+// sharedPaths are the configured folders of the content readme keeps once per project
+func sharedPaths(cfg config.Config) docs.SharedPaths {
+	return docs.SharedPaths{
+		Changelog:   cfg.ChangelogPath,
+		CustomPages: cfg.CustomPagesPath,
+		Blocks:      cfg.BlocksPath,
+	}
+}
+
+// reservedFolders returns the top-level folders of a docs root that hold project-wide content rather than a category.
+// Such a folder deeper in the root would be synced as docs, and one named like a configured category would hide it.
+func reservedFolders(root string, cfg config.Config) ([]string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+
+	var reserved []string
+	for _, folder := range []struct {
+		key, path string
+	}{
+		{"changelog_path", cfg.ChangelogPath},
+		{"custom_pages_path", cfg.CustomPagesPath},
+		{"blocks_path", cfg.BlocksPath},
+	} {
+		if folder.path == "" {
+			continue
+		}
+		abs, err := filepath.Abs(folder.path)
+		if err != nil {
+			return nil, xerrors.Errorf(": %w", err)
+		}
+		rel, err := filepath.Rel(absRoot, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue // outside the docs root
+		}
+		if rel == "." || strings.ContainsRune(rel, filepath.Separator) {
+			return nil, xerrors.New(fmt.Sprintf("%v %v must be outside the docs root %v or a top-level folder of it", folder.key, folder.path, root))
+		}
+		for _, cat := range cfg.Categories {
+			if cat.Slug == rel {
+				return nil, xerrors.New(fmt.Sprintf("%v %v is also configured as category \"%v\"", folder.key, folder.path, cat.Slug))
+			}
+		}
+		reserved = append(reserved, rel)
+	}
+	return reserved, nil
+}
+
+// syncBlocks walks the project-wide content of the current project and syncs its blocks,
+// which docs in every version may embed. It returns the content and a client for the rest of it.
+func (r *run) syncBlocks(ctx context.Context) (docs.Shared, *readme.Client, error) {
+	log := logging.FromContext(ctx)
+
+	shared, err := docs.WalkShared(ctx, sharedPaths(r.cfg))
+	if err != nil {
+		return docs.Shared{}, nil, xerrors.Errorf(": %w", err)
+	}
+	for _, skipped := range shared.Skipped {
+		log.Info("Skipping file", "path", skipped.Path, "reason", skipped.Reason)
+	}
+
+	client, err := readme.NewClient(ctx, r.cfg.Key, "")
+	if err != nil {
+		return docs.Shared{}, nil, xerrors.Errorf(": %w", err)
+	}
+
+	docOpts := r.docOptions(nil)
+	for _, block := range shared.Blocks {
+		block := block
+		if !r.changed(block.Roots, block.Filepath) {
+			continue
+		}
+		if err := r.report.Track(report.Result{Project: r.project, Kind: "block", Slug: block.Tag}, func() (report.Action, error) {
+			return docs.ProcessBlock(ctx, client, block, docOpts)
+		}); err != nil {
+			return docs.Shared{}, nil, xerrors.Errorf(": %w", err)
+		}
+	}
+	return shared, client, nil
+}
+
+// syncEntries syncs the changelog and custom pages of the current project and prunes project-wide content.
+// Blocks are only pruned when every version synced, so that no remaining doc still embeds them.
+func (r *run) syncEntries(ctx context.Context, client *readme.Client, shared docs.Shared, versionsOK bool) error {
+	docOpts := r.docOptions(nil)
+
+	if shared.ReferencesBlocks() {
+		known, err := knownBlocks(ctx, client, shared)
+		if err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		if err := shared.ValidateBlockReferences(known); err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+	}
+
+	for _, changelog := range shared.Changelogs {
+		changelog := changelog
+		if !r.changed(changelog.Roots, changelog.Filepath) {
+			continue
+		}
+		if err := r.report.Track(report.Result{Project: r.project, Kind: "changelog", Slug: changelog.Slug}, func() (report.Action, error) {
+			return docs.ProcessChangelog(ctx, client, changelog, docOpts)
+		}); err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+	}
+
+	for _, page := range shared.CustomPages {
+		page := page
+		if !r.changed(page.Roots, page.Filepath) {
+			continue
+		}
+		if err := r.report.Track(report.Result{Project: r.project, Kind: "custom-page", Slug: page.Slug}, func() (report.Action, error) {
+			return docs.ProcessCustomPage(ctx, client, page, docOpts)
+		}); err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+	}
+
+	// nothing can have disappeared locally when no file was deleted or renamed
+	if r.since != nil && !r.since.removed {
+		return nil
+	}
+	del := func(kind, slug string, fn func(context.Context, string) error) error {
+		return r.del(ctx, report.Result{Project: r.project, Kind: kind, Slug: slug}, fn)
+	}
+
+	// each kind is only managed once its folder is configured
+	if shared.Changelogs != nil {
+		changelogs, err := client.GetChangelogs(ctx)
+		if err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		for _, changelog := range changelogs {
+			if _, found := shared.Changelogs[changelog.Slug]; !found {
+				if err := del("changelog", changelog.Slug, client.DeleteChangelog); err != nil {
+					return xerrors.Errorf(": %w", err)
+				}
+			}
+		}
+	}
+
+	if shared.CustomPages != nil {
+		pages, err := client.GetCustomPages(ctx)
+		if err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		for _, page := range pages {
+			if _, found := shared.CustomPages[page.Slug]; !found {
+				if err := del("custom-page", page.Slug, client.DeleteCustomPage); err != nil {
+					return xerrors.Errorf(": %w", err)
+				}
+			}
+		}
+	}
+
+	if shared.Blocks != nil && versionsOK {
+		blocks, err := client.GetBlocks(ctx)
+		if err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		for _, block := range blocks {
+			if _, found := shared.Blocks[block.Tag]; !found {
+				if err := del("block", block.Tag, client.DeleteBlock); err != nil {
+					return xerrors.Errorf(": %w", err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// knownBlocks are the tags content may embed: the local blocks when they are managed here, otherwise those in readme
+func knownBlocks(ctx context.Context, client *readme.Client, shared docs.Shared) (map[string]struct{}, error) {
+	known := make(map[string]struct{})
+	if shared.Blocks != nil {
+		for tag := range shared.Blocks {
+			known[tag] = struct{}{}
+		}
+		return known, nil
+	}
+
+	blocks, err := client.GetBlocks(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+	for _, block := range blocks {
+		known[block.Tag] = struct{}{}
+	}
+	return known, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// ignoreFileName matches the docs package, a changed ignore file can bring back files git does not report
+const ignoreFileName = ".readmesyncignore"
+
+// changeSet is what changed since a git ref, or since the last sync in watch mode, for incremental syncs
+type changeSet struct {
+	changed map[string]struct{} // absolute paths added, modified, copied or renamed to, including untracked files
+	deleted map[string]struct{} // absolute paths deleted or renamed away
+	removed bool                // files were deleted or renamed away, so remote items may need pruning
+}
+
+// gitChanges compares the working tree, including uncommitted and untracked files, with ref.
+// Only the local repository is read.
+func gitChanges(ref string) (*changeSet, error) {
+	top, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+	root := strings.TrimSpace(string(top))
+
+	if _, err := git("rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, xerrors.New("-since: unknown git ref " + ref)
+	}
+
+	// -z output is status, path and, for renames and copies, the new path, all nul separated
+	diff, err := git("-C", root, "diff", "--name-status", "-M", "-z", ref, "--")
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+
+	cs := &changeSet{changed: make(map[string]struct{}), deleted: make(map[string]struct{})}
+	if err := cs.addNameStatus(root, diff); err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+
+	untracked, err := git("-C", root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, xerrors.Errorf(": %w", err)
+	}
+	for _, rel := range strings.Split(string(untracked), "\x00") {
+		if rel != "" {
+			cs.add(root, rel)
+		}
+	}
+
+	return cs, nil
+}
+
+// addNameStatus records the output of git diff --name-status -z run in root
+func (cs *changeSet) addNameStatus(root string, diff []byte) error {
+	fields := strings.Split(strings.TrimSuffix(string(diff), "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		status, path := fields[i], fields[i+1]
+		switch status[0] {
+		case 'R':
+			cs.del(root, path)
+			fallthrough
+		case 'C':
+			if i+2 >= len(fields) {
+				return xerrors.New("unexpected git diff output")
+			}
+			cs.add(root, fields[i+2])
+			i++
+		case 'D':
+			cs.del(root, path)
+		default:
+			cs.add(root, path)
+		}
+	}
+	return nil
+}
+
+// add records a change to the file at rel, relative to root and slash separated as git reports it
+func (cs *changeSet) add(root, rel string) {
+	cs.changed[filepath.Join(root, filepath.FromSlash(rel))] = struct{}{}
+}
+
+// del records that the file at rel, relative to root, was deleted or renamed away
+func (cs *changeSet) del(root, rel string) {
+	cs.deleted[filepath.Join(root, filepath.FromSlash(rel))] = struct{}{}
+	cs.removed = true
+}
+
+func git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, xerrors.Errorf("git %v: %v: %w", strings.Join(args, " "), strings.TrimSpace(stderr.String()), err)
+	}
+	return out, nil
+}
+
+// has reports whether the file at path changed
+func (cs *changeSet) has(path string) bool {
+	real, err := realPath(path)
+	if err != nil {
+		return true // err on the side of syncing
+	}
+	_, found := cs.changed[real]
+	return found
+}
+
+// realPath makes path absolute and resolves symlinks where it exists,
+// as git and the file watcher report paths below the real root
+func realPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", xerrors.Errorf(": %w", err)
+	}
+	if real, err := filepath.EvalSymlinks(abs); err == nil {
+		return real, nil
+	}
+	return abs, nil
+}
+
+// any reports whether any of the files at paths changed
+func (cs *changeSet) any(paths ...string) bool {
+	for _, path := range paths {
+		if cs.has(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// uncovered reports whether a file was deleted from one of the layered roots at the same relative path
+// as one of paths. The file at path then takes its place even though it did not change itself,
+// such as a docs root file once the overlay file replacing it is deleted.
+func (cs *changeSet) uncovered(roots []string, paths ...string) bool {
+	if len(cs.deleted) == 0 || len(roots) < 2 {
+		return false
+	}
+
+	realRoots := make([]string, 0, len(roots))
+	for _, root := range roots {
+		real, err := realPath(root)
+		if err != nil {
+			return true // err on the side of syncing
+		}
+		realRoots = append(realRoots, real)
+	}
+
+	for _, path := range paths {
+		real, err := realPath(path)
+		if err != nil {
+			return true
+		}
+		for _, root := range realRoots {
+			rel, err := filepath.Rel(root, real)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			for _, other := range realRoots {
+				if _, found := cs.deleted[filepath.Join(other, rel)]; found {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// fullSyncReason returns why the whole tree must be compared even though only some files changed,
+// or an empty string. Configuration changes can affect every doc, and a changed ignore file can
+// bring back files git does not report.
+func (cs *changeSet) fullSyncReason(configFiles []string) string {
+	for _, file := range configFiles {
+		if file != "" && cs.has(file) {
+			return file + " changed"
+		}
+	}
+	for path := range cs.changed {
+		if filepath.Base(path) == ignoreFileName {
+			return path + " changed"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAddNameStatus(t *testing.T) {
+	root := filepath.FromSlash("/repo")
+	abs := func(rel string) string { return filepath.Join(root, filepath.FromSlash(rel)) }
+
+	tests := []struct {
+		name    string
+		diff    string
+		changed []string
+		deleted []string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:    "modified and added",
+			diff:    "M\x00docs/a.md\x00A\x00docs/b.md\x00",
+			changed: []string{"docs/a.md", "docs/b.md"},
+		},
+		{
+			name:    "deleted",
+			diff:    "D\x00docs/gone.md\x00",
+			deleted: []string{"docs/gone.md"},
+		},
+		{
+			name:    "rename consumes three fields",
+			diff:    "R100\x00docs/old.md\x00docs/new.md\x00M\x00docs/c.md\x00",
+			changed: []string{"docs/new.md", "docs/c.md"},
+			deleted: []string{"docs/old.md"},
+		},
+		{
+			name:    "copy consumes three fields and keeps the source",
+			diff:    "C75\x00docs/src.md\x00docs/copy.md\x00D\x00docs/d.md\x00",
+			changed: []string{"docs/copy.md"},
+			deleted: []string{"docs/d.md"},
+		},
+		{
+			name:    "paths with spaces",
+			diff:    "R090\x00docs/old name.md\x00docs/new name.md\x00",
+			changed: []string{"docs/new name.md"},
+			deleted: []string{"docs/old name.md"},
+		},
+		{
+			name:    "truncated rename",
+			diff:    "R100\x00docs/old.md\x00",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &changeSet{changed: make(map[string]struct{}), deleted: make(map[string]struct{})}
+			err := cs.addNameStatus(root, []byte(tt.diff))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("addNameStatus: %v", err)
+			}
+
+			set := func(rels []string) map[string]struct{} {
+				paths := make(map[string]struct{})
+				for _, rel := range rels {
+					paths[abs(rel)] = struct{}{}
+				}
+				return paths
+			}
+			if diff := cmp.Diff(set(tt.changed), cs.changed); diff != "" {
+				t.Errorf("changed (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(set(tt.deleted), cs.deleted); diff != "" {
+				t.Errorf("deleted (-want +got):\n%v", diff)
+			}
+			if want := len(tt.deleted) > 0; cs.removed != want {
+				t.Errorf("removed = %v, want %v", cs.removed, want)
+			}
+		})
+	}
+}
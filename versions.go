@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/rolflewis/readme-sync/readme"
+	"golang.org/x/xerrors"
+)
+
+// versionCmd manages readme versions so release pipelines can fork a version before syncing into it
+func versionCmd(ctx context.Context, lf *logFlags, args []string) error {
+	if len(args) < 1 {
+		return xerrors.New("version requires one of list, get, create, update or delete")
+	}
+
+	fs := flag.NewFlagSet("version "+args[0], flag.ContinueOnError)
+	var v readme.Version
+	fs.StringVar(&v.Version, "version", "", "version to act on")
+	fs.StringVar(&v.From, "from", "", "version to fork the docs from (create only)")
+	fs.StringVar(&v.Codename, "codename", "", "codename of the version")
+	fs.BoolVar(&v.IsStable, "stable", false, "make this the stable version")
+	fs.BoolVar(&v.IsBeta, "beta", false, "mark the version as beta")
+	fs.BoolVar(&v.IsHidden, "hidden", false, "hide the version from the version picker")
+	fs.BoolVar(&v.IsDeprecated, "deprecated", false, "mark the version as deprecated")
+	var rename string
+	fs.StringVar(&rename, "rename", "", "new name for the version (update only)")
+
+	ef := &envFlags{}
+	ef.register(fs)
+	lf.register(fs)
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+
+	ctx, err := lf.apply(ctx)
+	if err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	log := lf.logger()
+
+	if err := ef.load(); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+
+	key, err := ef.key()
+	if err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+
+	client, err := readme.NewClient(ctx, key, "")
+	if err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+
+	if args[0] == "list" {
+		versions, err := client.GetVersions(ctx)
+		if err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		for _, v := range versions {
+			printVersion(v)
+		}
+		return nil
+	}
+
+	if v.Version == "" {
+		return xerrors.New("-version is required")
+	}
+
+	switch args[0] {
+	case "get":
+		existing, err := client.GetVersion(ctx, v.Version)
+		if err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		if existing.Version == "" {
+			return xerrors.New(fmt.Sprintf("version \"%v\" not found", v.Version))
+		}
+		printVersion(existing)
+	case "create":
+		log.Info("Creating version", "version", v.Version, "from", v.From)
+		if err := client.CreateVersion(ctx, v); err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+	case "update":
+		existing, err := client.GetVersion(ctx, v.Version)
+		if err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		if existing.Version == "" {
+			return xerrors.New(fmt.Sprintf("version \"%v\" not found", v.Version))
+		}
+
+		// only change what was asked for, everything else keeps its current value
+		updated := existing
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "codename":
+				updated.Codename = v.Codename
+			case "stable":
+				updated.IsStable = v.IsStable
+			case "beta":
+				updated.IsBeta = v.IsBeta
+			case "hidden":
+				updated.IsHidden = v.IsHidden
+			case "deprecated":
+				updated.IsDeprecated = v.IsDeprecated
+			case "rename":
+				updated.Version = rename
+			}
+		})
+
+		log.Info("Updating version", "version", v.Version)
+		if err := client.UpdateVersion(ctx, v.Version, updated); err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+	case "delete":
+		log.Info("Deleting version", "version", v.Version)
+		if err := client.DeleteVersion(ctx, v.Version); err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+	default:
+		return xerrors.New("unknown version command: " + args[0])
+	}
+	return nil
+}
+
+func printVersion(v readme.Version) {
+	var flags string
+	for _, f := range []struct {
+		set  bool
+		name string
+	}{{v.IsStable, "stable"}, {v.IsBeta, "beta"}, {v.IsHidden, "hidden"}, {v.IsDeprecated, "deprecated"}} {
+		if f.set {
+			flags += " " + f.name
+		}
+	}
+	fmt.Printf("%v\t%v\t%v\n", v.Version, v.Codename, flags)
+}
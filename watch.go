@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rolflewis/readme-sync/config"
+	"github.com/rolflewis/readme-sync/docs"
+	"github.com/rolflewis/readme-sync/logging"
+	"github.com/rolflewis/readme-sync/report"
+	"golang.org/x/xerrors"
+)
+
+// watchCmd syncs once, then syncs the files that change under the docs paths until interrupted,
+// so authors can preview their edits on a staging version
+func watchCmd(ctx context.Context, lf *logFlags, args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	cf := &configFlags{}
+	cf.register(flags)
+	ef := &envFlags{}
+	ef.register(flags)
+	vars := make(varFlags)
+	flags.Var(vars, "var", "template variable as name=value, overrides config and environment (repeatable)")
+	var showDiff bool
+	flags.BoolVar(&showDiff, "diff", true, "log the changed fields and a unified diff of the body for every update")
+	var debounce time.Duration
+	flags.DurationVar(&debounce, "debounce", 500*time.Millisecond, "wait this long after the last change before syncing")
+	lf.register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	if debounce <= 0 {
+		return xerrors.New("-debounce must be positive")
+	}
+
+	ctx, err := lf.apply(ctx)
+	if err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	log := lf.logger()
+
+	if err := ef.load(); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+
+	cfg, err := loadSyncConfig(cf, ef)
+	if err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+
+	statePath := specStatePath(cfg)
+	specState, err := docs.LoadSpecState(statePath)
+	if err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+	defer watcher.Close()
+
+	w := &watch{watcher: watcher, env: ef.paths()}
+	if err := w.add(cfg); err != nil {
+		return xerrors.Errorf(": %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	r := run{
+		vars:      vars,
+		specState: specState,
+		statePath: statePath,
+		diff:      showDiff,
+		color:     lf.color(),
+	}
+	sync := func(changes *changeSet) {
+		r.since, r.report = changes, &report.Collector{}
+		failed := r.syncAll(ctx, cfg)
+		summarize(log, r.report, failed)
+		log.Info("Watching for changes", "paths", strings.Join(w.roots, ", "))
+	}
+	sync(nil)
+
+	pending := make(map[string]bool) // path to whether it was removed or renamed away
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Warn("File watcher error", "error", err)
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !w.relevant(event.Name) {
+				continue
+			}
+			log.Debug("File changed", "path", event.Name, "op", event.Op.String())
+			pending[event.Name] = pending[event.Name] || event.Op&(fsnotify.Remove|fsnotify.Rename) != 0
+
+			// directories created or moved in are watched too, along with the files already inside them
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					files, err := w.addTree(event.Name)
+					if err != nil {
+						log.Warn("Cannot watch directory", "path", event.Name, "error", err)
+					}
+					for _, file := range files {
+						if _, found := pending[file]; !found {
+							pending[file] = false
+						}
+					}
+				}
+			}
+			timer.Reset(debounce)
+
+		case <-timer.C:
+			changes := &changeSet{changed: make(map[string]struct{}), deleted: make(map[string]struct{})}
+			for path, removed := range pending {
+				changes.changed[path] = struct{}{}
+				// an editor saving through a temporary file removes and recreates it
+				if _, err := os.Lstat(path); removed && os.IsNotExist(err) {
+					changes.deleted[path] = struct{}{}
+					changes.removed = true
+				}
+			}
+			pending = make(map[string]bool)
+
+			// configuration and environment changes can affect every item
+			settings := append([]string{cfg.File}, ef.paths()...)
+			if changes.any(ef.paths()...) {
+				if err := ef.load(); err != nil {
+					log.Error("Cannot reload the environment files", "error", fmt.Sprintf("%+v", err))
+				}
+			}
+			if changes.any(settings...) {
+				if reloaded, err := loadSyncConfig(cf, ef); err != nil {
+					log.Error("Keeping the previous configuration, the changed one is invalid", "error", fmt.Sprintf("%+v", err))
+					for _, file := range settings {
+						if real, err := realPath(file); err == nil {
+							delete(changes.changed, real) // no reason for a full sync
+						}
+					}
+				} else {
+					cfg = reloaded
+					if err := w.add(cfg); err != nil {
+						log.Warn("Cannot watch the configured paths", "error", err)
+					}
+				}
+			}
+			if reason := changes.fullSyncReason(settings); reason != "" {
+				log.Info("Running a full sync", "reason", reason)
+				changes = nil
+			}
+			sync(changes)
+		}
+	}
+}
+
+// watch tracks the directories and files a configuration syncs from
+type watch struct {
+	watcher *fsnotify.Watcher
+	roots   []string            // docs roots, overlays and project-wide content folders, watched recursively
+	files   map[string]struct{} // configuration, environment files and specifications, watched through their directories
+	env     []string            // environment files, which may not exist yet
+}
+
+// add watches every path cfg syncs from, replacing the previous roots but not removing their watches,
+// which relevant then ignores
+func (w *watch) add(cfg config.Config) error {
+	w.roots, w.files = nil, make(map[string]struct{})
+	addFile := func(path string) error {
+		real, err := realPath(path)
+		if err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		w.files[real] = struct{}{}
+		// editors often replace files on save, which ends a watch on the file itself
+		if err := w.watcher.Add(filepath.Dir(real)); err != nil {
+			return xerrors.Errorf("%v: %w", path, err)
+		}
+		return nil
+	}
+
+	for _, file := range append([]string{cfg.File}, w.env...) {
+		if file == "" {
+			continue
+		}
+		if err := addFile(file); err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+	}
+	addRoot := func(root string) error {
+		real, err := realPath(root)
+		if err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		if _, err := w.addTree(real); err != nil {
+			return xerrors.Errorf(": %w", err)
+		}
+		w.roots = append(w.roots, real)
+		return nil
+	}
+
+	for _, project := range cfg.SyncProjects() {
+		for _, root := range []string{project.ChangelogPath, project.CustomPagesPath, project.BlocksPath} {
+			if root == "" {
+				continue
+			}
+			if err := addRoot(root); err != nil {
+				return xerrors.Errorf(": %w", err)
+			}
+		}
+		for _, target := range project.Targets() {
+			path := project.Path
+			if target.Path != "" {
+				path = target.Path
+			}
+			for _, root := range []string{path, target.Overlay} {
+				if root == "" {
+					continue
+				}
+				if err := addRoot(root); err != nil {
+					return xerrors.Errorf(": %w", err)
+				}
+			}
+			for _, spec := range target.Specs {
+				if err := addFile(spec.Path); err != nil {
+					return xerrors.Errorf(": %w", err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addTree watches dir and every directory below it, returning the files found
+func (w *watch) addTree(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, path)
+			return nil
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		return w.watcher.Add(path)
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("%v: %w", dir, err)
+	}
+	return files, nil
+}
+
+// relevant reports whether path is below a docs root or is a watched file,
+// as watching the directory of a file reports its neighbours too
+func (w *watch) relevant(path string) bool {
+	if _, found := w.files[path]; found {
+		return true
+	}
+	for _, root := range w.roots {
+		if path == root || strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// summarize logs what a sync in watch mode did, as there are no reports to read
+func summarize(log *logging.Logger, c *report.Collector, failed []string) {
+	counts := make(map[report.Action]int)
+	for _, result := range c.Results() {
+		counts[result.Action]++
+	}
+	attrs := []any{}
+	for _, action := range []report.Action{report.Created, report.Updated, report.Deleted, report.Unchanged, report.Failed} {
+		if counts[action] > 0 {
+			attrs = append(attrs, string(action), counts[action])
+		}
+	}
+	if len(failed) > 0 {
+		log.Error("Sync failed", append(attrs, "for", strings.Join(failed, ", "))...)
+		return
+	}
+	log.Info("Sync complete", attrs...)
+}